@@ -2,7 +2,7 @@ package gogenerate
 
 import (
 	"context"
-	"strings"
+	"errors"
 
 	"github.com/yaroher/surrealdb.go.orm/pkg/migrator"
 )
@@ -30,5 +30,5 @@ func AutoMigrate(ctx context.Context, db migrator.DB, dir string) (string, error
 }
 
 func isNoChanges(err error) bool {
-	return err != nil && strings.Contains(err.Error(), "no changes detected")
+	return errors.Is(err, migrator.ErrNoChanges)
 }
@@ -4,6 +4,7 @@ package gogenerate
 
 import (
 	"github.com/yaroher/surrealdb.go.orm/pkg/migrator"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -23,6 +24,33 @@ func (a Account) Schema() AccountSchema {
 	}
 }
 
+func (a Account) ToMap() map[string]any {
+	out := make(map[string]any, 2)
+	if !orm.IsZero(a.ID) {
+		out["id"] = a.ID
+	}
+	out["name"] = a.Name
+	return out
+}
+
+func (a *Account) FromMap(row map[string]any) error {
+	if err := orm.DecodeField(row, "id", &a.ID); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "name", &a.Name); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a Account) MarshalCBOR() ([]byte, error) {
+	return orm.MarshalCBORMap(a.ToMap())
+}
+
+func (a *Account) UnmarshalCBOR(data []byte) error {
+	return orm.UnmarshalCBORMap(data, a)
+}
+
 type PostSchema struct {
 	ID        qb.Field[string]
 	Title     qb.Field[string]
@@ -45,6 +73,45 @@ func (p Post) Schema() PostSchema {
 	}
 }
 
+func (p Post) ToMap() map[string]any {
+	out := make(map[string]any, 5)
+	if !orm.IsZero(p.ID) {
+		out["id"] = p.ID
+	}
+	out["title"] = p.Title
+	out["body"] = p.Body
+	out["published"] = p.Published
+	out["user"] = p.User
+	return out
+}
+
+func (p *Post) FromMap(row map[string]any) error {
+	if err := orm.DecodeField(row, "id", &p.ID); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "title", &p.Title); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "body", &p.Body); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "published", &p.Published); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "user", &p.User); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (p Post) MarshalCBOR() ([]byte, error) {
+	return orm.MarshalCBORMap(p.ToMap())
+}
+
+func (p *Post) UnmarshalCBOR(data []byte) error {
+	return orm.UnmarshalCBORMap(data, p)
+}
+
 type UserSchema struct {
 	ID        qb.Field[string]
 	FirstName qb.Field[string]
@@ -65,6 +132,41 @@ func (u User) Schema() UserSchema {
 	}
 }
 
+func (u User) ToMap() map[string]any {
+	out := make(map[string]any, 4)
+	if !orm.IsZero(u.ID) {
+		out["id"] = u.ID
+	}
+	out["first_name"] = u.FirstName
+	out["last_name"] = u.LastName
+	out["email"] = u.Email
+	return out
+}
+
+func (u *User) FromMap(row map[string]any) error {
+	if err := orm.DecodeField(row, "id", &u.ID); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "first_name", &u.FirstName); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "last_name", &u.LastName); err != nil {
+		return err
+	}
+	if err := orm.DecodeField(row, "email", &u.Email); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u User) MarshalCBOR() ([]byte, error) {
+	return orm.MarshalCBORMap(u.ToMap())
+}
+
+func (u *User) UnmarshalCBOR(data []byte) error {
+	return orm.UnmarshalCBORMap(data, u)
+}
+
 type UserAccountSchema struct {
 	Note qb.Field[string]
 }
@@ -87,6 +189,39 @@ func (u UserAccount) EdgeOut() qb.Table {
 	return qb.T("account")
 }
 
+func (u UserAccount) ToMap() map[string]any {
+	out := make(map[string]any, 1)
+	out["note"] = u.Note
+	return out
+}
+
+func (u *UserAccount) FromMap(row map[string]any) error {
+	if err := orm.DecodeField(row, "note", &u.Note); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (u UserAccount) MarshalCBOR() ([]byte, error) {
+	return orm.MarshalCBORMap(u.ToMap())
+}
+
+func (u *UserAccount) UnmarshalCBOR(data []byte) error {
+	return orm.UnmarshalCBORMap(data, u)
+}
+
+func (u UserAccount) Relate(from orm.Ref[User], to orm.Ref[Account]) *qb.RelateBuilder {
+	return orm.RelateEdge(from.RecordID(), u.Table(), to.RecordID(), u.ToMap())
+}
+
+func (u UserAccount) OutOf(from orm.Ref[User]) orm.Query[Account] {
+	return orm.Outgoing[UserAccount, Account](from.RecordID())
+}
+
+func (u UserAccount) InOf(to orm.Ref[Account]) orm.Query[User] {
+	return orm.Incoming[UserAccount, User](to.RecordID())
+}
+
 func Resources() migrator.ResourceSet {
 	res := migrator.NewResourceSet()
 	res.AddTable("account", qb.DefineTableName("account").PermissionsFull())
@@ -3,6 +3,7 @@ package gogenerate
 import (
 	"context"
 
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -20,14 +21,7 @@ func NewRepository(db DB) *Repository {
 }
 
 func (r *Repository) CreateUser(ctx context.Context, user User) error {
-	schema := (User{}).Schema()
-	payload := map[string]any{
-		schema.ID.Name:        user.ID,
-		schema.FirstName.Name: user.FirstName,
-		schema.LastName.Name:  user.LastName,
-		schema.Email.Name:     user.Email,
-	}
-	q := qb.Create((User{}).Table()).Content(payload).Build()
+	q := qb.Create((User{}).Table()).Content(user.ToMap()).Build()
 	return r.db.Exec(ctx, q)
 }
 
@@ -36,11 +30,11 @@ func (r *Repository) ListUsers(ctx context.Context) ([]User, error) {
 	q := qb.Select(schema.ID, schema.FirstName, schema.LastName, schema.Email).
 		From((User{}).Table()).
 		Build()
-	var out []User
-	if err := r.db.Query(ctx, q, &out); err != nil {
+	var rows []map[string]any
+	if err := r.db.Query(ctx, q, &rows); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return orm.DecodeRows[User](rows)
 }
 
 func (r *Repository) FindUserByEmail(ctx context.Context, email string) ([]User, error) {
@@ -49,9 +43,9 @@ func (r *Repository) FindUserByEmail(ctx context.Context, email string) ([]User,
 		From((User{}).Table()).
 		Where(schema.Email.Eq(email)).
 		Build()
-	var out []User
-	if err := r.db.Query(ctx, q, &out); err != nil {
+	var rows []map[string]any
+	if err := r.db.Query(ctx, q, &rows); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return orm.DecodeRows[User](rows)
 }
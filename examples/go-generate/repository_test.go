@@ -5,6 +5,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -13,6 +14,7 @@ type mockDB struct {
 	lastQuery QueryCapture
 	execErr   error
 	queryErr  error
+	rows      []map[string]any
 }
 
 type QueryCapture struct {
@@ -27,6 +29,9 @@ func (m *mockDB) Exec(ctx context.Context, query qb.Query) error {
 
 func (m *mockDB) Query(ctx context.Context, query qb.Query, out any) error {
 	m.lastQuery = QueryCapture{Query: query, Out: out}
+	if rows, ok := out.(*[]map[string]any); ok {
+		*rows = m.rows
+	}
 	return m.queryErr
 }
 
@@ -85,10 +90,46 @@ func TestRepositoryFindUserByEmail(t *testing.T) {
 		t.Fatalf("find by email: %v", err)
 	}
 
-	if db.lastQuery.Query.Text != "SELECT id, first_name, last_name, email FROM users WHERE (email = $p1)" {
+	if db.lastQuery.Query.Text != "SELECT id, first_name, last_name, email FROM users WHERE email = $p1" {
 		t.Fatalf("unexpected query: %s", db.lastQuery.Query.Text)
 	}
 	if got := db.lastQuery.Query.Args["p1"]; got != "ana@example.com" {
 		t.Fatalf("unexpected arg: %v", got)
 	}
 }
+
+func TestRepositoryListUsersDecodesRows(t *testing.T) {
+	db := &mockDB{rows: []map[string]any{
+		{"id": "users:1", "first_name": "Ana", "last_name": "Fox", "email": "ana@example.com"},
+	}}
+	repo := NewRepository(db)
+
+	users, err := repo.ListUsers(context.Background())
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	want := []User{{ID: "users:1", FirstName: "Ana", LastName: "Fox", Email: "ana@example.com"}}
+	if !reflect.DeepEqual(users, want) {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestUserAccountEdgeHelpers(t *testing.T) {
+	user := orm.NewSimpleID[User]("ann")
+	account := orm.NewSimpleID[Account]("main")
+
+	q := (UserAccount{Note: "owner"}).Relate(user, account).Build()
+	if q.Text != "RELATE $p1 -> user_account -> $p2 SET note = $p3" {
+		t.Fatalf("unexpected relate: %s", q.Text)
+	}
+	if q.Args["p1"] != user.RecordID() || q.Args["p2"] != account.RecordID() || q.Args["p3"] != "owner" {
+		t.Fatalf("unexpected args: %v", q.Args)
+	}
+
+	if got := (UserAccount{}).OutOf(user).Build().Text; got != "SELECT * FROM $p1->user_account->account" {
+		t.Fatalf("unexpected out query: %s", got)
+	}
+	if got := (UserAccount{}).InOf(account).Build().Text; got != "SELECT * FROM $p1<-user_account<-users" {
+		t.Fatalf("unexpected in query: %s", got)
+	}
+}
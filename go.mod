@@ -3,15 +3,15 @@ module github.com/yaroher/surrealdb.go.orm
 go 1.25.4
 
 require (
+	github.com/fxamacker/cbor/v2 v2.7.0
+	github.com/gorilla/websocket v1.5.3
 	github.com/spf13/cobra v1.8.1
 	github.com/surrealdb/surrealdb.go v1.0.0
 	google.golang.org/protobuf v1.36.11
 )
 
 require (
-	github.com/fxamacker/cbor/v2 v2.7.0 // indirect
 	github.com/gofrs/uuid v4.4.0+incompatible // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/x448/float16 v0.8.4 // indirect
@@ -159,6 +159,13 @@ func init() {
 	migrateCmd.PersistentFlags().String("db", "", "database")
 	migrateCmd.PersistentFlags().String("username", "", "username")
 	migrateCmd.PersistentFlags().String("password", "", "password")
+	migrateCmd.PersistentFlags().String("auth", "", "auth method: root|namespace|database|record|token (default: by --ns/--db)")
+	migrateCmd.PersistentFlags().String("access", "", "record access method for --auth record")
+	migrateCmd.PersistentFlags().StringToString("access-var", nil, "record access variable key=value (repeatable)")
+	migrateCmd.PersistentFlags().Bool("signup", false, "sign up instead of signing in with --auth record")
+	migrateCmd.PersistentFlags().String("token", "", "JWT for --auth token (prefer SURREAL_ORM_TOKEN)")
+	migrateCmd.PersistentFlags().Duration("connect-timeout", 30*time.Second, "timeout for connecting and signing in (0 for none)")
+	migrateCmd.PersistentFlags().Duration("query-timeout", 0, "timeout for each query (0 for none)")
 	migrateCmd.PersistentFlags().Bool("force", false, "force non-interactive behavior")
 	migrateCmd.PersistentFlags().Bool("two-way", true, "generate two-way migrations")
 	migrateCmd.PersistentFlags().String("rename-strategy", "prompt", "rename strategy: prompt|rename|delete|keep")
@@ -178,17 +185,69 @@ func init() {
 }
 
 var connectFn = func(ctx context.Context, cfg migrator.Config) (migrator.DB, func(), error) {
-	client, err := surreal.Connect(ctx, cfg.DSN, cfg.NS, cfg.DB, cfg.Username, cfg.Password)
+	method, err := surreal.ParseAuthMethod(cfg.Auth)
 	if err != nil {
 		return nil, func() {}, err
 	}
+	vars := make(map[string]any, len(cfg.AccessVars))
+	for k, v := range cfg.AccessVars {
+		vars[k] = v
+	}
+	session, err := surreal.Open(ctx, cfg.DSN, cfg.NS, cfg.DB, surreal.Credentials{
+		Method:   method,
+		Username: cfg.Username,
+		Password: cfg.Password,
+		Access:   cfg.Access,
+		Vars:     vars,
+		SignUp:   cfg.SignUp,
+		Token:    cfg.Token,
+	})
+	if err != nil {
+		return nil, func() {}, err
+	}
+	session.QueryTimeout = cfg.QueryTimeout
 	cleanup := func() {
-		_ = client.Close(context.Background())
+		_ = session.Close(context.Background())
+	}
+	return session, cleanup, nil
+}
+
+// envFlags maps connection flags to the environment variables used when
+// the flag is not given.
+var envFlags = map[string]string{
+	"dsn":             "SURREAL_ORM_DSN",
+	"ns":              "SURREAL_ORM_NS",
+	"db":              "SURREAL_ORM_DB",
+	"username":        "SURREAL_ORM_USERNAME",
+	"password":        "SURREAL_ORM_PASSWORD",
+	"auth":            "SURREAL_ORM_AUTH",
+	"access":          "SURREAL_ORM_ACCESS",
+	"access-var":      "SURREAL_ORM_ACCESS_VARS",
+	"signup":          "SURREAL_ORM_SIGNUP",
+	"token":           "SURREAL_ORM_TOKEN",
+	"connect-timeout": "SURREAL_ORM_CONNECT_TIMEOUT",
+	"query-timeout":   "SURREAL_ORM_QUERY_TIMEOUT",
+}
+
+func applyEnv(cmd *cobra.Command) error {
+	for name, env := range envFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || f.Changed {
+			continue
+		}
+		if v, ok := os.LookupEnv(env); ok {
+			if err := f.Value.Set(v); err != nil {
+				return fmt.Errorf("%s: %w", env, err)
+			}
+		}
 	}
-	return surreal.Adapter{DB: client}, cleanup, nil
+	return nil
 }
 
 func buildMigrator(cmd *cobra.Command) (*migrator.Migrator, context.Context, func(), error) {
+	if err := applyEnv(cmd); err != nil {
+		return nil, nil, func() {}, err
+	}
 	dir, _ := cmd.Flags().GetString("dir")
 	mode, _ := cmd.Flags().GetString("mode")
 	dsn, _ := cmd.Flags().GetString("dsn")
@@ -196,6 +255,13 @@ func buildMigrator(cmd *cobra.Command) (*migrator.Migrator, context.Context, fun
 	dbName, _ := cmd.Flags().GetString("db")
 	user, _ := cmd.Flags().GetString("username")
 	pass, _ := cmd.Flags().GetString("password")
+	auth, _ := cmd.Flags().GetString("auth")
+	access, _ := cmd.Flags().GetString("access")
+	accessVars, _ := cmd.Flags().GetStringToString("access-var")
+	signUp, _ := cmd.Flags().GetBool("signup")
+	token, _ := cmd.Flags().GetString("token")
+	connectTimeout, _ := cmd.Flags().GetDuration("connect-timeout")
+	queryTimeout, _ := cmd.Flags().GetDuration("query-timeout")
 	force, _ := cmd.Flags().GetBool("force")
 	twoWay, _ := cmd.Flags().GetBool("two-way")
 	renameStrategy, _ := cmd.Flags().GetString("rename-strategy")
@@ -214,15 +280,27 @@ func buildMigrator(cmd *cobra.Command) (*migrator.Migrator, context.Context, fun
 		DB:             dbName,
 		Username:       user,
 		Password:       pass,
+		Auth:           auth,
+		Access:         access,
+		AccessVars:     accessVars,
+		SignUp:         signUp,
+		Token:          token,
 		Force:          force,
 		TwoWay:         twoWay,
 		RenameStrategy: renameStrategy,
 		RenameExpr:     renameExpr,
 		GrantsAlways:   grantsAlways,
+		ConnectTimeout: connectTimeout,
+		QueryTimeout:   queryTimeout,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	db, closeFn, err := connectFn(ctx, cfg)
+	ctx, cancel := context.WithCancel(context.Background())
+	connectCtx, connectCancel := ctx, context.CancelFunc(func() {})
+	if cfg.ConnectTimeout > 0 {
+		connectCtx, connectCancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+	}
+	db, closeFn, err := connectFn(connectCtx, cfg)
+	connectCancel()
 	if err != nil {
 		cancel()
 		return nil, nil, func() {}, err
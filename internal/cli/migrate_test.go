@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/yaroher/surrealdb.go.orm/pkg/migrator"
+	"github.com/yaroher/surrealdb.go.orm/pkg/ormtest"
 )
 
 type stubDB struct {
@@ -81,6 +83,68 @@ func TestBuildMigratorSuccess(t *testing.T) {
 	}
 }
 
+func TestBuildMigratorAuthFromEnv(t *testing.T) {
+	var got migrator.Config
+	orig := connectFn
+	connectFn = func(ctx context.Context, cfg migrator.Config) (migrator.DB, func(), error) {
+		got = cfg
+		return stubDB{}, func() {}, nil
+	}
+	t.Cleanup(func() { connectFn = orig })
+	cmd := &cobra.Command{}
+	setCmdFlags(t, cmd, t.TempDir())
+	_ = cmd.Flags().Set("access", "account")
+	t.Setenv("SURREAL_ORM_AUTH", "record")
+	t.Setenv("SURREAL_ORM_ACCESS", "ignored")
+	t.Setenv("SURREAL_ORM_ACCESS_VARS", "email=a@b.c,pass=secret")
+	t.Setenv("SURREAL_ORM_SIGNUP", "true")
+
+	_, _, cleanup, err := buildMigrator(cmd)
+	if err != nil {
+		t.Fatalf("buildMigrator: %v", err)
+	}
+	cleanup()
+	if got.Auth != "record" || !got.SignUp || got.AccessVars["email"] != "a@b.c" || got.AccessVars["pass"] != "secret" {
+		t.Fatalf("expected auth settings from env, got %+v", got)
+	}
+	if got.Access != "account" {
+		t.Fatalf("expected flag to take precedence over env, got %q", got.Access)
+	}
+}
+
+func TestBuildMigratorTimeouts(t *testing.T) {
+	var (
+		got         migrator.Config
+		hasDeadline bool
+	)
+	orig := connectFn
+	connectFn = func(ctx context.Context, cfg migrator.Config) (migrator.DB, func(), error) {
+		got = cfg
+		_, hasDeadline = ctx.Deadline()
+		return stubDB{}, func() {}, nil
+	}
+	t.Cleanup(func() { connectFn = orig })
+	cmd := &cobra.Command{}
+	setCmdFlags(t, cmd, t.TempDir())
+	_ = cmd.Flags().Set("connect-timeout", "5s")
+	t.Setenv("SURREAL_ORM_QUERY_TIMEOUT", "2s")
+
+	_, ctx, cleanup, err := buildMigrator(cmd)
+	if err != nil {
+		t.Fatalf("buildMigrator: %v", err)
+	}
+	defer cleanup()
+	if got.ConnectTimeout != 5*time.Second || got.QueryTimeout != 2*time.Second {
+		t.Fatalf("unexpected timeouts: connect %v, query %v", got.ConnectTimeout, got.QueryTimeout)
+	}
+	if !hasDeadline {
+		t.Fatalf("expected the connect context to carry the connect timeout")
+	}
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatalf("expected the command context to outlive the connect timeout")
+	}
+}
+
 func TestGenerateCommand(t *testing.T) {
 	dir := t.TempDir()
 	src := `package sample
@@ -111,12 +175,7 @@ type User struct{ Name string }
 	if err := os.WriteFile(filepath.Join(codeDir, "model.go"), []byte(src), 0o644); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	withConnectStub(t, stubDB{fn: func(sql string) ([]map[string]any, error) {
-		if strings.HasPrefix(sql, "INFO FOR DB") {
-			return []map[string]any{{}}, nil
-		}
-		return nil, nil
-	}})
+	withConnectStub(t, ormtest.New())
 	setCmdFlags(t, migrateGenerateCmd, dir)
 	_ = migrateGenerateCmd.Flags().Set("code", codeDir)
 	_ = migrateGenerateCmd.Flags().Set("name", "init")
@@ -173,12 +232,8 @@ func TestMigrateUpDownResetCommands(t *testing.T) {
 		t.Fatalf("write: %v", err)
 	}
 
-	withConnectStub(t, stubDB{fn: func(sql string) ([]map[string]any, error) {
-		if strings.HasPrefix(sql, "SELECT id, checksum") {
-			return []map[string]any{{"id": "001_init", "checksum": "x", "applied_at": "now"}}, nil
-		}
-		return nil, nil
-	}})
+	db := ormtest.New()
+	withConnectStub(t, db)
 	setCmdFlags(t, migrateUpCmd, dir)
 	setCmdFlags(t, migrateDownCmd, dir)
 	setCmdFlags(t, migrateResetCmd, dir)
@@ -188,10 +243,22 @@ func TestMigrateUpDownResetCommands(t *testing.T) {
 	if err := migrateUpCmd.RunE(migrateUpCmd, nil); err != nil {
 		t.Fatalf("up: %v", err)
 	}
+	if got := len(db.Records("_migrations")); got != 1 {
+		t.Fatalf("expected 1 applied migration after up, got %d", got)
+	}
 	if err := migrateDownCmd.RunE(migrateDownCmd, nil); err != nil {
 		t.Fatalf("down: %v", err)
 	}
+	if got := len(db.Records("_migrations")); got != 0 {
+		t.Fatalf("expected no applied migrations after down, got %d", got)
+	}
+	if err := migrateUpCmd.RunE(migrateUpCmd, nil); err != nil {
+		t.Fatalf("up again: %v", err)
+	}
 	if err := migrateResetCmd.RunE(migrateResetCmd, nil); err != nil {
 		t.Fatalf("reset: %v", err)
 	}
+	if got := len(db.Records("_migrations")); got != 0 {
+		t.Fatalf("expected no applied migrations after reset, got %d", got)
+	}
 }
@@ -28,7 +28,8 @@ func BuildResourceSet(models []Model) migrator.ResourceSet {
 		applyPermissionsTable(table, model.Permissions)
 		res.AddTable(model.Table, table)
 
-		for _, field := range model.Fields {
+		fields := append(append([]Field(nil), model.Fields...), implicitFields(model)...)
+		for _, field := range fields {
 			fieldStmt := qb.DefineFieldName(field.DBName, model.Table)
 			if t := inferSurrealType(field.Type, field, model); t != "" {
 				fieldStmt.Type(t)
@@ -39,8 +40,8 @@ func BuildResourceSet(models []Model) migrator.ResourceSet {
 			if field.AssertExpr != "" {
 				fieldStmt.Assert(qb.RawCond(field.AssertExpr))
 			}
-			if field.DefaultExpr != "" {
-				fieldStmt.DefaultExpr(qb.Raw(field.DefaultExpr))
+			if def := fieldDefault(field); def != "" {
+				fieldStmt.DefaultExpr(qb.Raw(def))
 			}
 			applyPermissionsField(fieldStmt, field.Permissions)
 			res.AddField(model.Table, field.DBName, fieldStmt)
@@ -54,6 +55,46 @@ func BuildResourceSet(models []Model) migrator.ResourceSet {
 	return res
 }
 
+// implicitFields returns the fields implied by model annotations (tenant,
+// soft_delete) that the struct does not declare itself.
+func implicitFields(model Model) []Field {
+	var out []Field
+	if model.Tenant != "" && !hasDBField(model, model.Tenant) {
+		out = append(out, Field{DBName: model.Tenant})
+	}
+	if model.SoftDelete != "" && !hasDBField(model, model.SoftDelete) {
+		out = append(out, Field{DBName: model.SoftDelete, TypeHint: "option<datetime>"})
+	}
+	return out
+}
+
+// versionField returns the field annotated with `orm:field version`.
+func versionField(model Model) (Field, bool) {
+	for _, field := range model.Fields {
+		if field.Version {
+			return field, true
+		}
+	}
+	return Field{}, false
+}
+
+// fieldDefault returns the DEFAULT expression; version fields start at 0.
+func fieldDefault(field Field) string {
+	if field.DefaultExpr == "" && field.Version {
+		return "0"
+	}
+	return field.DefaultExpr
+}
+
+func hasDBField(model Model, dbName string) bool {
+	for _, field := range model.Fields {
+		if field.DBName == dbName {
+			return true
+		}
+	}
+	return false
+}
+
 func addAccessResource(res *migrator.ResourceSet, ac AccessConfig) {
 	stmt := qb.DefineAccess(ac.Name)
 	if ac.Overwrite {
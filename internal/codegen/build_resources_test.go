@@ -78,3 +78,29 @@ func TestBuildResourceSetPermissions(t *testing.T) {
 		t.Fatalf("unexpected field permissions: %s", q)
 	}
 }
+
+func TestBuildResourceSetTenantField(t *testing.T) {
+	model := Model{Kind: "node", Table: "doc", Tenant: "tenant_id", Fields: []Field{{Name: "Title", Type: "string", DBName: "title"}}}
+	res := BuildResourceSet([]Model{model})
+	def, ok := res.Fields["doc"]["tenant_id"]
+	if !ok {
+		t.Fatalf("expected tenant field in resources")
+	}
+	if got := qb.Build(def.Statement).Text; got != "DEFINE FIELD tenant_id ON TABLE doc" {
+		t.Fatalf("unexpected tenant field: %s", got)
+	}
+
+	model.Fields = append(model.Fields, Field{Name: "TenantID", Type: "string", DBName: "tenant_id"})
+	res = BuildResourceSet([]Model{model})
+	if got := qb.Build(res.Fields["doc"]["tenant_id"].Statement).Text; got != "DEFINE FIELD tenant_id ON TABLE doc TYPE string" {
+		t.Fatalf("expected declared tenant field to win, got %s", got)
+	}
+}
+
+func TestBuildResourceSetVersionField(t *testing.T) {
+	model := Model{Kind: "node", Table: "doc", Fields: []Field{{Name: "Rev", Type: "int64", DBName: "rev", Version: true}}}
+	res := BuildResourceSet([]Model{model})
+	if got := qb.Build(res.Fields["doc"]["rev"].Statement).Text; got != "DEFINE FIELD rev ON TABLE doc TYPE int DEFAULT 0" {
+		t.Fatalf("unexpected version field: %s", got)
+	}
+}
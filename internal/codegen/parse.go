@@ -43,9 +43,30 @@ func ParseDir(dir string) (Package, error) {
 	if pkgName == "" {
 		return Package{}, fmt.Errorf("no package found in %s", dir)
 	}
+	resolveIDTables(models)
 	return Package{Name: pkgName, Models: models, Imports: usedImports}, nil
 }
 
+// resolveIDTables points ID and SimpleID fields at the table of the model
+// they reference, which may differ from its snake-cased name via table=.
+// Qualified references name models of other packages and are left alone.
+func resolveIDTables(models []Model) {
+	tables := make(map[string]string, len(models))
+	for _, m := range models {
+		tables[m.Name] = m.Table
+	}
+	for i := range models {
+		for j, f := range models[i].Fields {
+			if !isIDType(f.Type) {
+				continue
+			}
+			if table, ok := tables[idModel(f.Type)]; ok {
+				models[i].Fields[j].IDTable = table
+			}
+		}
+	}
+}
+
 func parseFile(file *ast.File, fset *token.FileSet, imports map[string]string, usedImports map[string]string) []Model {
 	var models []Model
 	for _, decl := range file.Decls {
@@ -96,10 +117,14 @@ func buildModel(name string, ann Annotation, st *ast.StructType, fset *token.Fil
 		RenameAll:   renameAll,
 		EdgeIn:      normalizeRef(ann.Args["in"]),
 		EdgeOut:     normalizeRef(ann.Args["out"]),
+		EdgeInType:  goTypeRef(ann.Args["in"]),
+		EdgeOutType: goTypeRef(ann.Args["out"]),
 		SchemaFull:  ann.Args["schemafull"] == "true",
 		SchemaLess:  ann.Args["schemaless"] == "true",
 		Drop:        ann.Args["drop"] == "true",
 		Permissions: ann.Args["permissions"],
+		Tenant:      ann.Args["tenant"],
+		SoftDelete:  ann.Args["soft_delete"],
 		Access:      parseAccessConfig(ann),
 	}
 
@@ -129,6 +154,7 @@ func buildModel(name string, ann Annotation, st *ast.StructType, fset *token.Fil
 				LinkOne:     fieldMeta["link_one"],
 				LinkMany:    fieldMeta["link_many"],
 				LinkSelf:    fieldMeta["link_self"],
+				Version:     fieldMeta["version"] == "true",
 			})
 		}
 	}
@@ -235,6 +261,15 @@ func normalizeRef(name string) string {
 	return toSnake(name)
 }
 
+// goTypeRef returns name if it looks like a Go type in the same package
+// (an exported identifier), so edge helpers can reference the model type.
+func goTypeRef(name string) string {
+	if name == "" || !token.IsExported(name) || !token.IsIdentifier(name) {
+		return ""
+	}
+	return name
+}
+
 func NormalizeRef(name string) string {
 	return normalizeRef(name)
 }
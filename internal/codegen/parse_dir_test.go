@@ -79,7 +79,7 @@ type Ignore struct{}
 	}
 
 	edge := pkg.Models[1]
-	if edge.Kind != "edge" || edge.EdgeIn != "user" || edge.EdgeOut != "account" {
+	if edge.Kind != "edge" || edge.EdgeIn != "user" || edge.EdgeOut != "account" || edge.EdgeInType != "User" || edge.EdgeOutType != "Account" {
 		t.Fatalf("unexpected edge model: %+v", edge)
 	}
 
@@ -89,6 +89,38 @@ type Ignore struct{}
 	}
 }
 
+func TestParseDirResolvesIDTables(t *testing.T) {
+	dir := t.TempDir()
+	src := `package sample
+
+import "github.com/yaroher/surrealdb.go.orm/pkg/orm"
+
+// orm:node table=accounts
+type Account struct{}
+
+// orm:node
+type Order struct {
+	Account orm.ID[Account, int]
+	Owner   orm.SimpleID[*Account]
+	Remote  orm.SimpleID[other.Account]
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "models.go"), []byte(src), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	pkg, err := ParseDir(dir)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	order := pkg.Models[1]
+	want := []string{"record<accounts>", "record<accounts>", "record<account>"}
+	for i, field := range order.Fields {
+		if got := inferSurrealType(field.Type, field, order); got != want[i] {
+			t.Fatalf("%s: expected %s, got %s", field.Name, want[i], got)
+		}
+	}
+}
+
 func TestMergeAnnotationArgs(t *testing.T) {
 	anns := []Annotation{
 		{Kind: "field", Args: map[string]string{"name": "a"}},
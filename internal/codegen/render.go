@@ -26,26 +26,51 @@ func RenderToBytes(pkg Package) ([]byte, error) {
 	buf.WriteString("package ")
 	buf.WriteString(pkg.Name)
 	buf.WriteString("\n\n")
-	renderImports(&buf, pkg.Imports)
+	renderImports(&buf, pkg.Imports, needsCodec(pkg.Models))
 
+	objects := map[string]bool{}
+	nodes := map[string]bool{}
+	for _, model := range pkg.Models {
+		switch model.Kind {
+		case "object":
+			objects[model.Name] = true
+		case "node":
+			nodes[model.Name] = true
+		}
+	}
 	for _, model := range pkg.Models {
 		renderModel(&buf, model)
+		if hasCodec(model) {
+			renderCodec(&buf, model, objects)
+		}
+		if model.Kind == "edge" && nodes[model.EdgeInType] && nodes[model.EdgeOutType] {
+			renderEdgeHelpers(&buf, model)
+		}
 	}
 	renderResources(&buf, pkg.Models)
+	renderTenantTables(&buf, pkg.Models)
 
 	return buf.Bytes(), nil
 }
 
-func renderImports(buf *bytes.Buffer, imports map[string]string) {
+func renderImports(buf *bytes.Buffer, imports map[string]string, withORM bool) {
 	buf.WriteString("import (\n")
 	buf.WriteString("\t\"github.com/yaroher/surrealdb.go.orm/pkg/qb\"\n")
 	buf.WriteString("\t\"github.com/yaroher/surrealdb.go.orm/pkg/migrator\"\n")
+	if withORM {
+		buf.WriteString("\t\"")
+		buf.WriteString(ormImportPath)
+		buf.WriteString("\"\n")
+	}
 
 	var keys []string
 	for name := range imports {
 		if name == "_" || name == "." {
 			continue
 		}
+		if withORM && name == "orm" && imports[name] == ormImportPath {
+			continue
+		}
 		keys = append(keys, name)
 	}
 	sort.Strings(keys)
@@ -114,6 +139,30 @@ func renderModel(buf *bytes.Buffer, model Model) {
 	buf.WriteString("\t}\n")
 	buf.WriteString("}\n\n")
 
+	if model.SoftDelete != "" {
+		buf.WriteString("func (")
+		buf.WriteString(recv)
+		buf.WriteString(" ")
+		buf.WriteString(model.Name)
+		buf.WriteString(") SoftDeleteField() string {\n")
+		buf.WriteString("\treturn \"")
+		buf.WriteString(model.SoftDelete)
+		buf.WriteString("\"\n")
+		buf.WriteString("}\n\n")
+	}
+
+	if field, ok := versionField(model); ok {
+		buf.WriteString("func (")
+		buf.WriteString(recv)
+		buf.WriteString(" ")
+		buf.WriteString(model.Name)
+		buf.WriteString(") VersionField() string {\n")
+		buf.WriteString("\treturn \"")
+		buf.WriteString(field.DBName)
+		buf.WriteString("\"\n")
+		buf.WriteString("}\n\n")
+	}
+
 	if model.Kind == "edge" {
 		buf.WriteString("func (")
 		buf.WriteString(recv)
@@ -137,6 +186,186 @@ func renderModel(buf *bytes.Buffer, model Model) {
 	}
 }
 
+const ormImportPath = "github.com/yaroher/surrealdb.go.orm/pkg/orm"
+
+func hasCodec(model Model) bool {
+	switch model.Kind {
+	case "node", "edge", "object":
+		return true
+	}
+	return false
+}
+
+func needsCodec(models []Model) bool {
+	for _, model := range models {
+		if hasCodec(model) {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCodec emits ToMap/FromMap and the CBOR methods built on them, so rows
+// are encoded by DBName without reflection. Nested object models are encoded
+// through their own ToMap/FromMap; unset IDs and links are left out.
+func renderCodec(buf *bytes.Buffer, model Model, objects map[string]bool) {
+	recv := receiverName(model.Name)
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" ")
+	buf.WriteString(model.Name)
+	buf.WriteString(") ToMap() map[string]any {\n")
+	buf.WriteString("\tout := make(map[string]any, ")
+	buf.WriteString(strconv.Itoa(len(model.Fields)))
+	buf.WriteString(")\n")
+	for _, field := range model.Fields {
+		value := recv + "." + field.Name
+		switch {
+		case objects[field.Type]:
+			value += ".ToMap()"
+		case objects[strings.TrimPrefix(field.Type, "[]")]:
+			value = "orm.EncodeSlice(" + value + ")"
+		case objects[strings.TrimPrefix(field.Type, "*")]:
+			value = "orm.EncodePtr(" + value + ")"
+		}
+		guard := ""
+		switch {
+		case field.DBName == "id":
+			guard = "!orm.IsZero(" + recv + "." + field.Name + ")"
+		case isLinkType(field.Type):
+			guard = "!" + recv + "." + field.Name + ".IsZero()"
+		}
+		if guard != "" {
+			buf.WriteString("\tif ")
+			buf.WriteString(guard)
+			buf.WriteString(" {\n\t")
+		}
+		buf.WriteString("\tout[")
+		buf.WriteString(strconv.Quote(field.DBName))
+		buf.WriteString("] = ")
+		buf.WriteString(value)
+		buf.WriteString("\n")
+		if guard != "" {
+			buf.WriteString("\t}\n")
+		}
+	}
+	buf.WriteString("\treturn out\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" *")
+	buf.WriteString(model.Name)
+	buf.WriteString(") FromMap(row map[string]any) error {\n")
+	for _, field := range model.Fields {
+		decode := "orm.DecodeField"
+		switch {
+		case objects[strings.TrimPrefix(field.Type, "[]")] && strings.HasPrefix(field.Type, "[]"):
+			decode = "orm.DecodeSlice"
+		case objects[strings.TrimPrefix(field.Type, "*")] && strings.HasPrefix(field.Type, "*"):
+			decode = "orm.DecodePtr"
+		}
+		buf.WriteString("\tif err := ")
+		buf.WriteString(decode)
+		buf.WriteString("(row, ")
+		buf.WriteString(strconv.Quote(field.DBName))
+		buf.WriteString(", &")
+		buf.WriteString(recv)
+		buf.WriteString(".")
+		buf.WriteString(field.Name)
+		buf.WriteString("); err != nil {\n")
+		buf.WriteString("\t\treturn err\n")
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" ")
+	buf.WriteString(model.Name)
+	buf.WriteString(") MarshalCBOR() ([]byte, error) {\n")
+	buf.WriteString("\treturn orm.MarshalCBORMap(")
+	buf.WriteString(recv)
+	buf.WriteString(".ToMap())\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" *")
+	buf.WriteString(model.Name)
+	buf.WriteString(") UnmarshalCBOR(data []byte) error {\n")
+	buf.WriteString("\treturn orm.UnmarshalCBORMap(data, ")
+	buf.WriteString(recv)
+	buf.WriteString(")\n")
+	buf.WriteString("}\n\n")
+}
+
+// renderEdgeHelpers emits typed RELATE and traversal helpers for an edge
+// whose in/out name node models of the same package.
+func renderEdgeHelpers(buf *bytes.Buffer, model Model) {
+	recv := receiverName(model.Name)
+	in, out := model.EdgeInType, model.EdgeOutType
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" ")
+	buf.WriteString(model.Name)
+	buf.WriteString(") Relate(from orm.Ref[")
+	buf.WriteString(in)
+	buf.WriteString("], to orm.Ref[")
+	buf.WriteString(out)
+	buf.WriteString("]) *qb.RelateBuilder {\n")
+	buf.WriteString("\treturn orm.RelateEdge(from.RecordID(), ")
+	buf.WriteString(recv)
+	buf.WriteString(".Table(), to.RecordID(), ")
+	buf.WriteString(recv)
+	buf.WriteString(".ToMap())\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" ")
+	buf.WriteString(model.Name)
+	buf.WriteString(") OutOf(from orm.Ref[")
+	buf.WriteString(in)
+	buf.WriteString("]) orm.Query[")
+	buf.WriteString(out)
+	buf.WriteString("] {\n")
+	buf.WriteString("\treturn orm.Outgoing[")
+	buf.WriteString(model.Name)
+	buf.WriteString(", ")
+	buf.WriteString(out)
+	buf.WriteString("](from.RecordID())\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("func (")
+	buf.WriteString(recv)
+	buf.WriteString(" ")
+	buf.WriteString(model.Name)
+	buf.WriteString(") InOf(to orm.Ref[")
+	buf.WriteString(out)
+	buf.WriteString("]) orm.Query[")
+	buf.WriteString(in)
+	buf.WriteString("] {\n")
+	buf.WriteString("\treturn orm.Incoming[")
+	buf.WriteString(model.Name)
+	buf.WriteString(", ")
+	buf.WriteString(in)
+	buf.WriteString("](to.RecordID())\n")
+	buf.WriteString("}\n\n")
+}
+
+func isLinkType(goType string) bool {
+	for _, link := range []string{"LinkOne[", "LinkMany[", "LinkSelf["} {
+		if strings.Contains(goType, link) {
+			return true
+		}
+	}
+	return false
+}
+
 func receiverName(typeName string) string {
 	if typeName == "" {
 		return "m"
@@ -191,6 +420,10 @@ func renderTableResources(buf *bytes.Buffer, model Model) {
 		renderFieldResource(buf, model, field)
 	}
 
+	for _, field := range implicitFields(model) {
+		renderFieldResource(buf, model, field)
+	}
+
 	if model.Kind == "edge" && model.EdgeIn != "" && model.EdgeOut != "" {
 		buf.WriteString("\tres.AddField(\"")
 		buf.WriteString(model.Table)
@@ -214,6 +447,29 @@ func renderTableResources(buf *bytes.Buffer, model Model) {
 	}
 }
 
+func renderTenantTables(buf *bytes.Buffer, models []Model) {
+	var scoped []Model
+	for _, model := range models {
+		if model.Tenant != "" {
+			scoped = append(scoped, model)
+		}
+	}
+	if len(scoped) == 0 {
+		return
+	}
+	buf.WriteString("func TenantTables() map[string]string {\n")
+	buf.WriteString("\treturn map[string]string{\n")
+	for _, model := range scoped {
+		buf.WriteString("\t\t\"")
+		buf.WriteString(model.Table)
+		buf.WriteString("\": \"")
+		buf.WriteString(model.Tenant)
+		buf.WriteString("\",\n")
+	}
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n\n")
+}
+
 func renderAccessResource(buf *bytes.Buffer, ac AccessConfig) {
 	if ac.Name == "" {
 		return
@@ -371,9 +627,9 @@ func renderFieldResource(buf *bytes.Buffer, model Model, field Field) {
 		buf.WriteString(strconv.Quote(field.AssertExpr))
 		buf.WriteString("))")
 	}
-	if field.DefaultExpr != "" {
+	if def := fieldDefault(field); def != "" {
 		buf.WriteString(".DefaultExpr(qb.Raw(")
-		buf.WriteString(strconv.Quote(field.DefaultExpr))
+		buf.WriteString(strconv.Quote(def))
 		buf.WriteString("))")
 	}
 	if field.Permissions != "" {
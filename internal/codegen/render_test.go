@@ -88,3 +88,159 @@ func TestRenderOutput(t *testing.T) {
 		t.Fatalf("did not expect underscore/dot imports to be rendered")
 	}
 }
+
+func TestRenderTenantTables(t *testing.T) {
+	pkg := Package{
+		Name: "sample",
+		Models: []Model{
+			{Name: "Doc", Kind: "node", Table: "doc", Tenant: "tenant_id"},
+			{Name: "Plan", Kind: "node", Table: "plan"},
+		},
+	}
+	out, err := RenderToBytes(pkg)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	text := string(out)
+	for _, c := range []string{
+		"func TenantTables() map[string]string {",
+		"\"doc\": \"tenant_id\",",
+		"res.AddField(\"doc\", \"tenant_id\", qb.DefineFieldName(\"tenant_id\", \"doc\"))",
+	} {
+		if !strings.Contains(text, c) {
+			t.Fatalf("expected output to contain %q", c)
+		}
+	}
+	if strings.Contains(text, "\"plan\": ") {
+		t.Fatalf("did not expect unscoped table in TenantTables")
+	}
+
+	out, err = RenderToBytes(Package{Name: "sample", Models: []Model{{Name: "Plan", Kind: "node", Table: "plan"}}})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(string(out), "TenantTables") {
+		t.Fatalf("did not expect TenantTables without scoped models")
+	}
+}
+
+func TestRenderSoftDelete(t *testing.T) {
+	pkg := Package{
+		Name:   "sample",
+		Models: []Model{{Name: "Doc", Kind: "node", Table: "doc", SoftDelete: "deleted_at"}},
+	}
+	out, err := RenderToBytes(pkg)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	text := string(out)
+	for _, c := range []string{
+		"func (d Doc) SoftDeleteField() string {\n\treturn \"deleted_at\"\n}",
+		"res.AddField(\"doc\", \"deleted_at\", qb.DefineFieldName(\"deleted_at\", \"doc\").Type(\"option<datetime>\"))",
+	} {
+		if !strings.Contains(text, c) {
+			t.Fatalf("expected output to contain %q", c)
+		}
+	}
+}
+
+func TestRenderCodec(t *testing.T) {
+	pkg := Package{
+		Name: "sample",
+		Models: []Model{
+			{Name: "Address", Kind: "object", Table: "address", Fields: []Field{
+				{Name: "City", Type: "string", DBName: "city"},
+			}},
+			{Name: "User", Kind: "node", Table: "user", Fields: []Field{
+				{Name: "ID", Type: "string", DBName: "id"},
+				{Name: "Home", Type: "Address", DBName: "home"},
+				{Name: "Work", Type: "*Address", DBName: "work"},
+				{Name: "Past", Type: "[]Address", DBName: "past_addresses"},
+				{Name: "Boss", Type: "orm.LinkOne[User]", DBName: "boss"},
+			}},
+		},
+	}
+	out, err := RenderToBytes(pkg)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	text := string(out)
+	for _, c := range []string{
+		"\"github.com/yaroher/surrealdb.go.orm/pkg/orm\"",
+		"func (u User) ToMap() map[string]any {",
+		"if !orm.IsZero(u.ID) {\n\t\tout[\"id\"] = u.ID\n\t}",
+		"out[\"home\"] = u.Home.ToMap()",
+		"out[\"work\"] = orm.EncodePtr(u.Work)",
+		"out[\"past_addresses\"] = orm.EncodeSlice(u.Past)",
+		"if !u.Boss.IsZero() {\n\t\tout[\"boss\"] = u.Boss\n\t}",
+		"orm.DecodeField(row, \"home\", &u.Home)",
+		"orm.DecodePtr(row, \"work\", &u.Work)",
+		"orm.DecodeSlice(row, \"past_addresses\", &u.Past)",
+		"func (u User) MarshalCBOR() ([]byte, error) {\n\treturn orm.MarshalCBORMap(u.ToMap())\n}",
+		"func (u *User) UnmarshalCBOR(data []byte) error {\n\treturn orm.UnmarshalCBORMap(data, u)\n}",
+		"func (a Address) ToMap() map[string]any {",
+	} {
+		if !strings.Contains(text, c) {
+			t.Fatalf("expected output to contain %q", c)
+		}
+	}
+
+	out, err = RenderToBytes(Package{Name: "sample", Models: []Model{{Name: "Acc", Kind: "access", Access: AccessConfig{Name: "acc"}}}})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if strings.Contains(string(out), "pkg/orm") {
+		t.Fatalf("did not expect orm import without codec models")
+	}
+}
+
+func TestRenderEdgeHelpers(t *testing.T) {
+	pkg := Package{
+		Name: "sample",
+		Models: []Model{
+			{Name: "User", Kind: "node", Table: "users"},
+			{Name: "Post", Kind: "node", Table: "post"},
+			{Name: "Wrote", Kind: "edge", Table: "wrote", EdgeIn: "user", EdgeOut: "post", EdgeInType: "User", EdgeOutType: "Post"},
+			{Name: "Loose", Kind: "edge", Table: "loose", EdgeIn: "a", EdgeOut: "b"},
+		},
+	}
+	out, err := RenderToBytes(pkg)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	text := string(out)
+	for _, c := range []string{
+		"func (w Wrote) Relate(from orm.Ref[User], to orm.Ref[Post]) *qb.RelateBuilder {\n\treturn orm.RelateEdge(from.RecordID(), w.Table(), to.RecordID(), w.ToMap())\n}",
+		"func (w Wrote) OutOf(from orm.Ref[User]) orm.Query[Post] {\n\treturn orm.Outgoing[Wrote, Post](from.RecordID())\n}",
+		"func (w Wrote) InOf(to orm.Ref[Post]) orm.Query[User] {\n\treturn orm.Incoming[Wrote, User](to.RecordID())\n}",
+	} {
+		if !strings.Contains(text, c) {
+			t.Fatalf("expected output to contain %q", c)
+		}
+	}
+	if strings.Contains(text, "func (l Loose) Relate") {
+		t.Fatalf("did not expect helpers for edges without model types")
+	}
+}
+
+func TestRenderVersionField(t *testing.T) {
+	pkg := Package{
+		Name: "sample",
+		Models: []Model{{Name: "Doc", Kind: "node", Table: "doc", Fields: []Field{
+			{Name: "Rev", Type: "int64", DBName: "rev", Version: true},
+		}}},
+	}
+	out, err := RenderToBytes(pkg)
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	text := string(out)
+	for _, c := range []string{
+		"func (d Doc) VersionField() string {\n\treturn \"rev\"\n}",
+		"res.AddField(\"doc\", \"rev\", qb.DefineFieldName(\"rev\", \"doc\").Type(\"int\").DefaultExpr(qb.Raw(\"0\")))",
+	} {
+		if !strings.Contains(text, c) {
+			t.Fatalf("expected output to contain %q", c)
+		}
+	}
+}
@@ -6,6 +6,9 @@ func inferSurrealType(goType string, field Field, model Model) string {
 	if field.TypeHint != "" {
 		return field.TypeHint
 	}
+	if field.Version {
+		return "int"
+	}
 	if field.LinkOne != "" {
 		return "record<" + normalizeRef(field.LinkOne) + ">"
 	}
@@ -36,8 +39,11 @@ func inferSurrealType(goType string, field Field, model Model) string {
 		}
 		return "array"
 	}
-	if strings.Contains(goType, "SimpleID") || strings.Contains(goType, "ID[") {
-		return "record<" + model.Table + ">"
+	if isIDType(goType) {
+		return "record<" + idTable(goType, field, model) + ">"
+	}
+	if kind, ok := geometryKind(goType); ok {
+		return kind
 	}
 	if strings.HasPrefix(goType, "[]") {
 		return "array"
@@ -66,6 +72,66 @@ func inferSurrealType(goType string, field Field, model Model) string {
 	return ""
 }
 
+var geometryKinds = map[string]string{
+	"Geometry":        "geometry<feature>",
+	"GeoPoint":        "geometry<point>",
+	"GeoLine":         "geometry<line>",
+	"GeoPolygon":      "geometry<polygon>",
+	"GeoMultiPoint":   "geometry<multipoint>",
+	"GeoMultiLine":    "geometry<multiline>",
+	"GeoMultiPolygon": "geometry<multipolygon>",
+	"GeoCollection":   "geometry<collection>",
+}
+
+func geometryKind(goType string) (string, bool) {
+	name := strings.TrimPrefix(goType, "*")
+	if idx := strings.LastIndex(name, "."); idx != -1 {
+		name = name[idx+1:]
+	}
+	kind, ok := geometryKinds[name]
+	return kind, ok
+}
+
+// idTable resolves the table of an ID[T, K] or SimpleID[T] field: the
+// table of the referenced model when ParseDir found it in the package, the
+// field's own model when T is that model, else T's name snake-cased.
+func idTable(goType string, field Field, model Model) string {
+	if field.IDTable != "" {
+		return field.IDTable
+	}
+	ref := unqualified(idModel(goType))
+	if ref == "" || ref == model.Name {
+		return model.Table
+	}
+	return normalizeRef(ref)
+}
+
+// idModel returns T of an ID[T, K] or SimpleID[T] type, package qualifier
+// included.
+func idModel(goType string) string {
+	start := strings.Index(goType, "[")
+	end := strings.LastIndex(goType, "]")
+	if start == -1 || end <= start+1 {
+		return ""
+	}
+	ref := goType[start+1 : end]
+	if idx := strings.Index(ref, ","); idx != -1 {
+		ref = ref[:idx]
+	}
+	return strings.TrimPrefix(strings.TrimSpace(ref), "*")
+}
+
+func unqualified(name string) string {
+	if dot := strings.LastIndex(name, "."); dot != -1 {
+		return name[dot+1:]
+	}
+	return name
+}
+
+func isIDType(goType string) bool {
+	return strings.Contains(goType, "SimpleID") || strings.Contains(goType, "ID[")
+}
+
 func extractGenericType(typeStr string) string {
 	start := strings.Index(typeStr, "[")
 	end := strings.LastIndex(typeStr, "]")
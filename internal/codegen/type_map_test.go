@@ -58,6 +58,30 @@ func TestInferSurrealType(t *testing.T) {
 			field:  Field{},
 			want:   "record<user>",
 		},
+		{
+			name:   "id referencing another model",
+			goType: "orm.ID[Account, int]",
+			field:  Field{},
+			want:   "record<account>",
+		},
+		{
+			name:   "id with qualified key type",
+			goType: "orm.ID[Account, time.Duration]",
+			field:  Field{},
+			want:   "record<account>",
+		},
+		{
+			name:   "simple id with qualified model",
+			goType: "orm.SimpleID[*models.Account]",
+			field:  Field{},
+			want:   "record<account>",
+		},
+		{
+			name:   "id resolved to the model table",
+			goType: "orm.ID[Account, int]",
+			field:  Field{IDTable: "accounts"},
+			want:   "record<accounts>",
+		},
 		{
 			name:   "simple id",
 			goType: "SimpleID[User]",
@@ -82,6 +106,24 @@ func TestInferSurrealType(t *testing.T) {
 			field:  Field{},
 			want:   "array",
 		},
+		{
+			name:   "geo point",
+			goType: "orm.GeoPoint",
+			field:  Field{},
+			want:   "geometry<point>",
+		},
+		{
+			name:   "geo polygon pointer",
+			goType: "*orm.GeoPolygon",
+			field:  Field{},
+			want:   "geometry<polygon>",
+		},
+		{
+			name:   "geometry any",
+			goType: "orm.Geometry",
+			field:  Field{},
+			want:   "geometry<feature>",
+		},
 		{
 			name:   "time",
 			goType: "time.Time",
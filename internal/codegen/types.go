@@ -18,10 +18,14 @@ type Model struct {
 	RenameAll   string
 	EdgeIn      string
 	EdgeOut     string
+	EdgeInType  string
+	EdgeOutType string
 	SchemaFull  bool
 	SchemaLess  bool
 	Drop        bool
 	Permissions string
+	Tenant      string
+	SoftDelete  string
 	Access      AccessConfig
 }
 
@@ -38,6 +42,10 @@ type Field struct {
 	LinkOne     string
 	LinkMany    string
 	LinkSelf    string
+	Version     bool
+	// IDTable is the table of the model an ID or SimpleID field references,
+	// filled in by ParseDir.
+	IDTable string
 }
 
 type AccessConfig struct {
@@ -2,28 +2,54 @@ package surreal
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	surrealdb "github.com/surrealdb/surrealdb.go"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 )
 
 var queryFn = surrealdb.Query[[]map[string]any]
 var fromEndpointFn = surrealdb.FromEndpointURLString
-var signInFn = func(ctx context.Context, db *surrealdb.DB, auth surrealdb.Auth) (any, error) {
+var signInFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
 	return db.SignIn(ctx, auth)
 }
 var useFn = func(ctx context.Context, db *surrealdb.DB, ns, dbName string) error {
 	return db.Use(ctx, ns, dbName)
 }
+var closeFn = func(ctx context.Context, db *surrealdb.DB) error {
+	return db.Close(ctx)
+}
 
-// Adapter wraps surrealdb.DB to satisfy migrator.DB.
+// Adapter wraps surrealdb.DB to satisfy migrator.DB. Errors are classified
+// with orm.ClassifyError; a failed multi-statement query is classified by
+// the statement that failed it, not by those it rolled back.
 type Adapter struct {
 	DB *surrealdb.DB
+	// Timeout bounds each query when positive; an expired timeout is
+	// reported as orm.ErrTimeout.
+	Timeout time.Duration
+}
+
+func (a Adapter) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.Timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, a.Timeout)
+}
+
+// Ping checks that the server answers a trivial query.
+func (a Adapter) Ping(ctx context.Context) error {
+	_, err := a.Query(ctx, "RETURN true", nil)
+	return err
 }
 
 func (a Adapter) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
 	res, err := queryFn(ctx, a.DB, sql, vars)
 	if err != nil {
-		return nil, err
+		return nil, orm.ClassifyError(err)
 	}
 	if res == nil {
 		return nil, nil
@@ -35,27 +61,57 @@ func (a Adapter) Query(ctx context.Context, sql string, vars map[string]any) ([]
 	return out, nil
 }
 
-// Connect establishes a SurrealDB connection and signs in if credentials provided.
+// QueryBatch runs a multi-statement query and returns one result per
+// statement with its rows, status, time and error. Statement errors are
+// reported in the results rather than as the returned error.
+func (a Adapter) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]orm.StatementResult, error) {
+	ctx, cancel := a.withTimeout(ctx)
+	defer cancel()
+	res, err := queryFn(ctx, a.DB, sql, vars)
+	if res == nil {
+		return nil, orm.ClassifyError(err)
+	}
+	out := make([]orm.StatementResult, len(*res))
+	for i, r := range *res {
+		out[i].Rows = r.Result
+		out[i].Status = r.Status
+		out[i].Time = r.Time
+		if r.Error != nil {
+			out[i].Err = orm.ClassifyError(r.Error)
+		}
+	}
+	return out, nil
+}
+
+// Connect establishes a SurrealDB connection and signs in if credentials
+// provided. Use Open for other authentication methods.
 func Connect(ctx context.Context, dsn, ns, db, user, pass string) (*surrealdb.DB, error) {
-	client, err := fromEndpointFn(ctx, dsn)
+	s, err := Open(ctx, dsn, ns, db, Credentials{Username: user, Password: pass})
 	if err != nil {
 		return nil, err
 	}
-	if user != "" || pass != "" {
-		_, err = signInFn(ctx, client, surrealdb.Auth{
-			Namespace: ns,
-			Database:  db,
-			Username:  user,
-			Password:  pass,
-		})
-		if err != nil {
-			return nil, err
+	return s.DB, nil
+}
+
+// ConnectRouter connects to the primary and every replica DSN with the same
+// namespace, database and credentials and returns an orm.Router over them,
+// with a function closing all connections.
+func ConnectRouter(ctx context.Context, primary string, replicas []string, ns, db, user, pass string) (*orm.Router, func(), error) {
+	var clients []*surrealdb.DB
+	closeAll := func() {
+		for _, c := range clients {
+			_ = closeFn(context.Background(), c)
 		}
 	}
-	if ns != "" || db != "" {
-		if err := useFn(ctx, client, ns, db); err != nil {
-			return nil, err
+	var adapters []orm.DB
+	for _, dsn := range append([]string{primary}, replicas...) {
+		client, err := Connect(ctx, dsn, ns, db, user, pass)
+		if err != nil {
+			closeAll()
+			return nil, func() {}, fmt.Errorf("surreal: connect %s: %w", dsn, err)
 		}
+		clients = append(clients, client)
+		adapters = append(adapters, Adapter{DB: client})
 	}
-	return client, nil
+	return orm.NewRouter(adapters[0], adapters[1:]...), closeAll, nil
 }
@@ -2,9 +2,11 @@ package surreal
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	surrealdb "github.com/surrealdb/surrealdb.go"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 )
 
 func TestAdapterQuery(t *testing.T) {
@@ -44,3 +46,59 @@ func TestAdapterQueryNil(t *testing.T) {
 		t.Fatalf("expected nil output")
 	}
 }
+
+func TestAdapterQueryBatch(t *testing.T) {
+	orig := queryFn
+	defer func() { queryFn = orig }()
+	queryFn = func(ctx context.Context, db *surrealdb.DB, sql string, vars map[string]any) (*[]surrealdb.QueryResult[[]map[string]any], error) {
+		res := []surrealdb.QueryResult[[]map[string]any]{
+			{Status: "OK", Time: "1ms", Result: []map[string]any{{"a": 1}}},
+			{Status: "ERR", Error: &surrealdb.QueryError{Message: "boom"}},
+		}
+		return &res, &surrealdb.QueryError{Message: "boom"}
+	}
+	out, err := (Adapter{}).QueryBatch(context.Background(), "a; b", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 2 || len(out[0].Rows) != 1 || out[0].Err != nil || out[0].Status != "OK" || out[0].Time != "1ms" {
+		t.Fatalf("unexpected results: %+v", out)
+	}
+	if out[1].Err == nil || out[1].Err.Error() != "boom" || out[1].Status != "ERR" {
+		t.Fatalf("expected statement error, got %v", out[1].Err)
+	}
+}
+
+func TestAdapterQueryClassifiesCausalStatement(t *testing.T) {
+	orig := queryFn
+	defer func() { queryFn = orig }()
+	queryFn = func(ctx context.Context, db *surrealdb.DB, sql string, vars map[string]any) (*[]surrealdb.QueryResult[[]map[string]any], error) {
+		failed := &surrealdb.QueryError{Message: "The query was not executed due to a failed transaction"}
+		unique := &surrealdb.QueryError{Message: "Database record `user:1` already exists"}
+		res := []surrealdb.QueryResult[[]map[string]any]{
+			{Status: "ERR", Error: failed},
+			{Status: "ERR", Error: unique},
+		}
+		return &res, errors.Join(errors.Join(nil, failed), unique)
+	}
+	_, err := (Adapter{}).Query(context.Background(), "BEGIN; CREATE user:2; CREATE user:1; COMMIT", nil)
+	if !errors.Is(err, orm.ErrUniqueViolation) || errors.Is(err, orm.ErrTransactionFailed) {
+		t.Fatalf("expected the unique violation, got %v", err)
+	}
+}
+
+func TestAdapterQueryClassifiesErrors(t *testing.T) {
+	orig := queryFn
+	defer func() { queryFn = orig }()
+	queryFn = func(ctx context.Context, db *surrealdb.DB, sql string, vars map[string]any) (*[]surrealdb.QueryResult[[]map[string]any], error) {
+		return nil, &surrealdb.QueryError{Message: "Database index `email` already contains 'a', with record `user:1`"}
+	}
+	_, err := (Adapter{}).Query(context.Background(), "CREATE user", nil)
+	if !errors.Is(err, orm.ErrUniqueViolation) {
+		t.Fatalf("expected unique violation, got %v", err)
+	}
+	var queryErr *surrealdb.QueryError
+	if !errors.As(err, &queryErr) {
+		t.Fatalf("expected driver error to remain reachable")
+	}
+}
@@ -0,0 +1,279 @@
+package surreal
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	surrealdb "github.com/surrealdb/surrealdb.go"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+)
+
+var signUpFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
+	return db.SignUp(ctx, auth)
+}
+var authenticateFn = func(ctx context.Context, db *surrealdb.DB, token string) error {
+	return db.Authenticate(ctx, token)
+}
+
+// AuthMethod selects how a connection authenticates.
+type AuthMethod string
+
+const (
+	// AuthAuto signs in a system user scoped to whichever of namespace and
+	// database are set, and skips sign-in without a username or password.
+	AuthAuto      AuthMethod = ""
+	AuthRoot      AuthMethod = "root"
+	AuthNamespace AuthMethod = "namespace"
+	AuthDatabase  AuthMethod = "database"
+	// AuthRecord signs in (or up) through a DEFINE ACCESS ... TYPE RECORD
+	// method, such as one generated from `orm:access type=record`.
+	AuthRecord AuthMethod = "record"
+	// AuthToken authenticates with a pre-issued JWT.
+	AuthToken AuthMethod = "token"
+)
+
+// ParseAuthMethod parses an AuthMethod name; "" and "auto" mean AuthAuto.
+func ParseAuthMethod(s string) (AuthMethod, error) {
+	switch m := AuthMethod(strings.ToLower(s)); m {
+	case AuthAuto, "auto":
+		return AuthAuto, nil
+	case AuthRoot, AuthNamespace, AuthDatabase, AuthRecord, AuthToken:
+		return m, nil
+	}
+	return "", fmt.Errorf("surreal: unknown auth method %q", s)
+}
+
+// ErrNoTokenSource is returned by Session.Refresh for token sessions
+// without a TokenSource.
+var ErrNoTokenSource = errors.New("surreal: token authentication has no token source to refresh from")
+
+// Credentials describe how a Session authenticates.
+type Credentials struct {
+	Method   AuthMethod
+	Username string
+	Password string
+	// Access names the record access method for AuthRecord, and Vars holds
+	// the variables its SIGNIN or SIGNUP clause reads, e.g. email and pass.
+	Access string
+	Vars   map[string]any
+	// SignUp signs up through the record access method instead of signing
+	// in. Refreshes always sign in.
+	SignUp bool
+	// Token is the JWT for AuthToken. TokenSource, if set, supplies the
+	// initial token when Token is empty and a new one on every refresh.
+	Token       string
+	TokenSource func(ctx context.Context) (string, error)
+}
+
+// Session is a connection together with the credentials it authenticated
+// with, so the session token can be renewed before it expires and the
+// connection re-established after it drops; see Query.
+type Session struct {
+	// DB is the client as opened; after a reconnect Client returns the
+	// current one.
+	DB          *surrealdb.DB
+	Namespace   string
+	Database    string
+	Credentials Credentials
+	// QueryTimeout bounds each query run through the session when positive.
+	QueryTimeout time.Duration
+	// ReconnectPolicy sets the attempts and backoff for re-establishing a lost
+	// connection; the zero value uses DefaultReconnectPolicy.
+	ReconnectPolicy orm.RetryPolicy
+
+	dsn string
+	// mu guards the current client and its generation, auth the token
+	// state, and redial serialises reconnects.
+	mu       sync.Mutex
+	client   *surrealdb.DB
+	gen      int
+	auth     sync.Mutex
+	token    string
+	signedUp bool
+	redial   sync.Mutex
+}
+
+// Open connects to dsn, authenticates with creds and selects the namespace
+// and database.
+func Open(ctx context.Context, dsn, ns, db string, creds Credentials) (*Session, error) {
+	s := &Session{Namespace: ns, Database: db, Credentials: creds, dsn: dsn}
+	client, err := s.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.DB, s.client = client, client
+	return s, nil
+}
+
+// dial connects to the session DSN, authenticates and selects the
+// namespace and database. The client is closed if either step fails.
+func (s *Session) dial(ctx context.Context) (*surrealdb.DB, error) {
+	client, err := fromEndpointFn(ctx, s.dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.prepare(ctx, client); err != nil {
+		closeCtx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+		defer cancel()
+		_ = closeFn(closeCtx, client)
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s *Session) prepare(ctx context.Context, client *surrealdb.DB) error {
+	if err := s.authenticate(ctx, client, false); err != nil {
+		return err
+	}
+	if s.Namespace != "" || s.Database != "" {
+		return useFn(ctx, client, s.Namespace, s.Database)
+	}
+	return nil
+}
+
+// Token returns the current session token, if authentication issued one.
+func (s *Session) Token() string {
+	s.auth.Lock()
+	defer s.auth.Unlock()
+	return s.token
+}
+
+// Refresh authenticates again to obtain a new session token.
+func (s *Session) Refresh(ctx context.Context) error {
+	client, _ := s.current()
+	return s.authenticate(ctx, client, true)
+}
+
+// authenticate signs client in with the session credentials. Token
+// sessions reuse the current token unless refreshing, and record sessions
+// sign in once they have signed up.
+func (s *Session) authenticate(ctx context.Context, client *surrealdb.DB, refresh bool) error {
+	s.auth.Lock()
+	defer s.auth.Unlock()
+	c := s.Credentials
+	var (
+		res any
+		err error
+	)
+	switch c.Method {
+	case AuthToken:
+		token := c.Token
+		if s.token != "" {
+			token = s.token
+		}
+		if c.TokenSource != nil && (refresh || token == "") {
+			if token, err = c.TokenSource(ctx); err != nil {
+				return err
+			}
+		} else if refresh {
+			return ErrNoTokenSource
+		}
+		if err := authenticateFn(ctx, client, token); err != nil {
+			return err
+		}
+		s.token = token
+		return nil
+	case AuthRecord:
+		params := make(map[string]any, len(c.Vars)+3)
+		for k, v := range c.Vars {
+			params[k] = v
+		}
+		params["NS"], params["DB"], params["AC"] = s.Namespace, s.Database, c.Access
+		if c.SignUp && !s.signedUp {
+			res, err = signUpFn(ctx, client, params)
+			s.signedUp = err == nil
+		} else {
+			res, err = signInFn(ctx, client, params)
+		}
+	case AuthRoot:
+		res, err = signInFn(ctx, client, surrealdb.Auth{Username: c.Username, Password: c.Password})
+	case AuthNamespace:
+		res, err = signInFn(ctx, client, surrealdb.Auth{Namespace: s.Namespace, Username: c.Username, Password: c.Password})
+	case AuthDatabase:
+		res, err = signInFn(ctx, client, surrealdb.Auth{Namespace: s.Namespace, Database: s.Database, Username: c.Username, Password: c.Password})
+	case AuthAuto:
+		if c.Username == "" && c.Password == "" {
+			return nil
+		}
+		res, err = signInFn(ctx, client, surrealdb.Auth{Namespace: s.Namespace, Database: s.Database, Username: c.Username, Password: c.Password})
+	default:
+		return fmt.Errorf("surreal: unknown auth method %q", c.Method)
+	}
+	if err != nil {
+		return err
+	}
+	if token, ok := res.(string); ok {
+		s.token = token
+	}
+	return nil
+}
+
+// minRefreshWait bounds how often RunRefresh refreshes tokens that expire
+// within the leeway.
+const minRefreshWait = 100 * time.Millisecond
+
+// RunRefresh refreshes the session leeway before its token expires, until
+// ctx is done. Failed refreshes are reported to onError, which may be nil,
+// and retried after leeway/2. It returns at once if the token carries no
+// expiry. A token already within the leeway is refreshed at once; if the
+// refreshed token is too, the next refresh waits leeway/2 instead of
+// looping.
+func (s *Session) RunRefresh(ctx context.Context, leeway time.Duration, onError func(error)) {
+	for refreshed := false; ; refreshed = true {
+		exp, ok := tokenExpiry(s.Token())
+		if !ok {
+			return
+		}
+		wait := time.Until(exp) - leeway
+		if refreshed {
+			wait = max(wait, leeway/2, minRefreshWait)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		for err := s.Refresh(ctx); err != nil; err = s.Refresh(ctx) {
+			if onError != nil {
+				onError(err)
+			}
+			if errors.Is(err, ErrNoTokenSource) {
+				return
+			}
+			retry := time.NewTimer(leeway / 2)
+			select {
+			case <-ctx.Done():
+				retry.Stop()
+				return
+			case <-retry.C:
+			}
+		}
+	}
+}
+
+// tokenExpiry reads the exp claim of a JWT without verifying it.
+func tokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Exp, 0), true
+}
@@ -0,0 +1,172 @@
+package surreal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	surrealdb "github.com/surrealdb/surrealdb.go"
+)
+
+// stubAuth replaces the driver calls and records what they received.
+func stubAuth(t *testing.T) *[]any {
+	t.Helper()
+	origFrom, origSignIn, origSignUp, origAuth, origUse := fromEndpointFn, signInFn, signUpFn, authenticateFn, useFn
+	t.Cleanup(func() {
+		fromEndpointFn, signInFn, signUpFn, authenticateFn, useFn = origFrom, origSignIn, origSignUp, origAuth, origUse
+	})
+	var calls []any
+	fromEndpointFn = func(ctx context.Context, dsn string) (*surrealdb.DB, error) {
+		return &surrealdb.DB{}, nil
+	}
+	signInFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
+		calls = append(calls, auth)
+		return "signin-token", nil
+	}
+	signUpFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
+		calls = append(calls, "signup", auth)
+		return "signup-token", nil
+	}
+	authenticateFn = func(ctx context.Context, db *surrealdb.DB, token string) error {
+		calls = append(calls, "authenticate "+token)
+		return nil
+	}
+	useFn = func(ctx context.Context, db *surrealdb.DB, ns, dbName string) error {
+		return nil
+	}
+	return &calls
+}
+
+func TestOpenSystemUserLevels(t *testing.T) {
+	cases := []struct {
+		method AuthMethod
+		want   surrealdb.Auth
+	}{
+		{AuthRoot, surrealdb.Auth{Username: "u", Password: "p"}},
+		{AuthNamespace, surrealdb.Auth{Namespace: "ns", Username: "u", Password: "p"}},
+		{AuthDatabase, surrealdb.Auth{Namespace: "ns", Database: "db", Username: "u", Password: "p"}},
+	}
+	for _, c := range cases {
+		calls := stubAuth(t)
+		s, err := Open(context.Background(), "ws://x", "ns", "db", Credentials{Method: c.method, Username: "u", Password: "p"})
+		if err != nil {
+			t.Fatalf("%s: %v", c.method, err)
+		}
+		if len(*calls) != 1 || (*calls)[0] != c.want || s.Token() != "signin-token" {
+			t.Fatalf("%s: unexpected sign-in %v", c.method, *calls)
+		}
+	}
+}
+
+func TestOpenRecordAccess(t *testing.T) {
+	calls := stubAuth(t)
+	s, err := Open(context.Background(), "ws://x", "ns", "db", Credentials{
+		Method: AuthRecord,
+		Access: "account",
+		Vars:   map[string]any{"email": "a@b.c", "pass": "secret"},
+		SignUp: true,
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	want := map[string]any{"NS": "ns", "DB": "db", "AC": "account", "email": "a@b.c", "pass": "secret"}
+	if len(*calls) != 2 || (*calls)[0] != "signup" || !reflect.DeepEqual((*calls)[1], want) || s.Token() != "signup-token" {
+		t.Fatalf("unexpected sign-up: %v", *calls)
+	}
+	if err := s.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if len(*calls) != 3 || !reflect.DeepEqual((*calls)[2], want) || s.Token() != "signin-token" {
+		t.Fatalf("expected refresh to sign in, got %v", *calls)
+	}
+}
+
+func TestOpenTokenAndRefresh(t *testing.T) {
+	calls := stubAuth(t)
+	s, err := Open(context.Background(), "ws://x", "ns", "db", Credentials{Method: AuthToken, Token: "jwt"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if len(*calls) != 1 || (*calls)[0] != "authenticate jwt" {
+		t.Fatalf("unexpected calls: %v", *calls)
+	}
+	if err := s.Refresh(context.Background()); !errors.Is(err, ErrNoTokenSource) {
+		t.Fatalf("expected ErrNoTokenSource, got %v", err)
+	}
+
+	s.Credentials.TokenSource = func(ctx context.Context) (string, error) { return "fresh", nil }
+	if err := s.Refresh(context.Background()); err != nil || s.Token() != "fresh" {
+		t.Fatalf("expected refreshed token, got %q %v", s.Token(), err)
+	}
+}
+
+func jwtExpiring(at time.Time) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, at.Unix())))
+	return "e30." + payload + ".sig"
+}
+
+func TestRunRefreshBeforeExpiry(t *testing.T) {
+	calls := stubAuth(t)
+	s, err := Open(context.Background(), "ws://x", "", "", Credentials{
+		Method: AuthToken,
+		Token:  jwtExpiring(time.Now()),
+		// The refreshed token has no expiry, which ends RunRefresh.
+		TokenSource: func(ctx context.Context) (string, error) { return "no-expiry", nil },
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		s.RunRefresh(context.Background(), time.Minute, nil)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("RunRefresh did not refresh the expiring token")
+	}
+	if s.Token() != "no-expiry" || len(*calls) != 2 {
+		t.Fatalf("unexpected refresh: %q %v", s.Token(), *calls)
+	}
+}
+
+func TestRunRefreshSpacesShortLivedTokens(t *testing.T) {
+	stubAuth(t)
+	var refreshes atomic.Int32
+	s, err := Open(context.Background(), "ws://x", "", "", Credentials{
+		Method: AuthToken,
+		Token:  jwtExpiring(time.Now()),
+		// Every refreshed token is already inside the leeway.
+		TokenSource: func(ctx context.Context) (string, error) {
+			refreshes.Add(1)
+			return jwtExpiring(time.Now()), nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	s.RunRefresh(ctx, 400*time.Millisecond, nil)
+	if got := refreshes.Load(); got < 1 || got > 3 {
+		t.Fatalf("expected a refresh every 200ms, got %d refreshes", got)
+	}
+}
+
+func TestParseAuthMethod(t *testing.T) {
+	if m, err := ParseAuthMethod("Record"); err != nil || m != AuthRecord {
+		t.Fatalf("unexpected method: %q %v", m, err)
+	}
+	if m, err := ParseAuthMethod("auto"); err != nil || m != AuthAuto {
+		t.Fatalf("unexpected method: %q %v", m, err)
+	}
+	if _, err := ParseAuthMethod("kerberos"); err == nil {
+		t.Fatalf("expected error for unknown method")
+	}
+}
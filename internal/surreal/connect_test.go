@@ -29,7 +29,7 @@ func TestConnectWithAuthAndUse(t *testing.T) {
 
 	signInCalled := false
 	useCalled := false
-	signInFn = func(ctx context.Context, db *surrealdb.DB, auth surrealdb.Auth) (any, error) {
+	signInFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
 		signInCalled = true
 		return nil, nil
 	}
@@ -52,39 +52,104 @@ func TestConnectWithAuthAndUse(t *testing.T) {
 func TestConnectAuthError(t *testing.T) {
 	origFrom := fromEndpointFn
 	origSignIn := signInFn
+	origClose := closeFn
 	defer func() {
 		fromEndpointFn = origFrom
 		signInFn = origSignIn
+		closeFn = origClose
 	}()
 
+	client := &surrealdb.DB{}
 	fromEndpointFn = func(ctx context.Context, dsn string) (*surrealdb.DB, error) {
-		return &surrealdb.DB{}, nil
+		return client, nil
 	}
-	signInFn = func(ctx context.Context, db *surrealdb.DB, auth surrealdb.Auth) (any, error) {
+	signInFn = func(ctx context.Context, db *surrealdb.DB, auth any) (any, error) {
 		return nil, context.Canceled
 	}
+	var closed []*surrealdb.DB
+	closeFn = func(ctx context.Context, db *surrealdb.DB) error {
+		closed = append(closed, db)
+		return nil
+	}
 
 	if _, err := Connect(context.Background(), "http://example", "ns", "db", "user", "pass"); err == nil {
 		t.Fatalf("expected signIn error")
 	}
+	if len(closed) != 1 || closed[0] != client {
+		t.Fatalf("expected the failed client to be closed, got %v", closed)
+	}
 }
 
 func TestConnectUseError(t *testing.T) {
 	origFrom := fromEndpointFn
 	origUse := useFn
+	origClose := closeFn
 	defer func() {
 		fromEndpointFn = origFrom
 		useFn = origUse
+		closeFn = origClose
 	}()
 
+	client := &surrealdb.DB{}
 	fromEndpointFn = func(ctx context.Context, dsn string) (*surrealdb.DB, error) {
-		return &surrealdb.DB{}, nil
+		return client, nil
 	}
 	useFn = func(ctx context.Context, db *surrealdb.DB, ns, dbName string) error {
 		return context.Canceled
 	}
+	var closed []*surrealdb.DB
+	closeFn = func(ctx context.Context, db *surrealdb.DB) error {
+		closed = append(closed, db)
+		return nil
+	}
 
 	if _, err := Connect(context.Background(), "http://example", "ns", "db", "", ""); err == nil {
 		t.Fatalf("expected use error")
 	}
+	if len(closed) != 1 || closed[0] != client {
+		t.Fatalf("expected the failed client to be closed, got %v", closed)
+	}
+}
+
+func TestConnectRouter(t *testing.T) {
+	origFrom := fromEndpointFn
+	origClose := closeFn
+	defer func() {
+		fromEndpointFn = origFrom
+		closeFn = origClose
+	}()
+
+	var dialed []string
+	fromEndpointFn = func(ctx context.Context, dsn string) (*surrealdb.DB, error) {
+		if dsn == "ws://bad" {
+			return nil, context.Canceled
+		}
+		dialed = append(dialed, dsn)
+		return &surrealdb.DB{}, nil
+	}
+	closed := 0
+	closeFn = func(ctx context.Context, db *surrealdb.DB) error {
+		closed++
+		return nil
+	}
+
+	router, cleanup, err := ConnectRouter(context.Background(), "ws://primary", []string{"ws://r1", "ws://r2"}, "", "", "", "")
+	if err != nil {
+		t.Fatalf("connect router: %v", err)
+	}
+	if len(dialed) != 3 || len(router.Replicas) != 2 {
+		t.Fatalf("expected primary and 2 replicas, got %v", dialed)
+	}
+	cleanup()
+	if closed != 3 {
+		t.Fatalf("expected 3 connections closed, got %d", closed)
+	}
+
+	closed = 0
+	if _, _, err := ConnectRouter(context.Background(), "ws://primary", []string{"ws://bad"}, "", "", "", ""); err == nil {
+		t.Fatalf("expected replica connect error")
+	}
+	if closed != 1 {
+		t.Fatalf("expected primary to be closed after failure, got %d", closed)
+	}
 }
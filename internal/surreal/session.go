@@ -0,0 +1,192 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	surrealdb "github.com/surrealdb/surrealdb.go"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+)
+
+// DefaultReconnectPolicy retries a lost connection five times with
+// backoff from 100ms to 5s.
+func DefaultReconnectPolicy() orm.RetryPolicy {
+	return orm.RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second}
+}
+
+// reconnectDelay returns the jittered backoff before redial number attempt
+// (1-based): a random duration in [d/2, d] where d = BaseDelay *
+// 2^(attempt-1), capped at MaxDelay.
+func reconnectDelay(p orm.RetryPolicy, attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// closeTimeout bounds closing a replaced client, whose peer may be gone.
+const closeTimeout = time.Second
+
+func (s *Session) current() (*surrealdb.DB, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.client, s.gen
+}
+
+// Client returns the current client, which changes when the session
+// reconnects.
+func (s *Session) Client() *surrealdb.DB {
+	client, _ := s.current()
+	return client
+}
+
+func (s *Session) adapter() (Adapter, int) {
+	client, gen := s.current()
+	return Adapter{DB: client, Timeout: s.QueryTimeout}, gen
+}
+
+// Query runs sql like Adapter.Query. If the connection turns out to be
+// lost, the session reconnects, signing in and selecting the namespace
+// and database again, and re-runs the query when it is a read according
+// to orm.IsRead; other statements may have been applied, so their error is
+// returned instead.
+func (s *Session) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	var rows []map[string]any
+	err := s.do(ctx, func(a Adapter) error {
+		var err error
+		rows, err = a.Query(ctx, sql, vars)
+		return err
+	})
+	return rows, err
+}
+
+// QueryBatch runs sql like Adapter.QueryBatch, reconnecting as Query does.
+func (s *Session) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]orm.StatementResult, error) {
+	var results []orm.StatementResult
+	err := s.do(ctx, func(a Adapter) error {
+		var err error
+		results, err = a.QueryBatch(ctx, sql, vars)
+		return err
+	})
+	return results, err
+}
+
+func (s *Session) do(ctx context.Context, call func(Adapter) error) error {
+	a, gen := s.adapter()
+	err := call(a)
+	if !s.connectionLost(ctx, a, err) {
+		return err
+	}
+	if rerr := s.reconnect(ctx, gen); rerr != nil {
+		return fmt.Errorf("surreal: reconnect after %v: %w", err, rerr)
+	}
+	if stmt, ok := orm.StatementFrom(ctx); !ok || !orm.IsRead(stmt) {
+		return err
+	}
+	a, _ = s.adapter()
+	return call(a)
+}
+
+// connectionLost reports whether err means the connection behind a is
+// gone: a connection error as orm.IsConnectionError tells it, or a query
+// timeout after which a ping fails too.
+func (s *Session) connectionLost(ctx context.Context, a Adapter, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, orm.ErrTimeout) {
+		return a.Ping(ctx) != nil
+	}
+	return orm.IsConnectionError(err)
+}
+
+// Reconnect replaces the connection with a new one, signed in and using
+// the session namespace and database, retrying with ReconnectPolicy.
+func (s *Session) Reconnect(ctx context.Context) error {
+	_, gen := s.current()
+	return s.reconnect(ctx, gen)
+}
+
+// reconnect redials unless another caller already replaced connection
+// generation gen.
+func (s *Session) reconnect(ctx context.Context, gen int) error {
+	s.redial.Lock()
+	defer s.redial.Unlock()
+	if _, current := s.current(); current != gen {
+		return nil
+	}
+	policy := s.ReconnectPolicy
+	if policy.MaxAttempts == 0 {
+		policy = DefaultReconnectPolicy()
+	}
+	for attempt := 1; ; attempt++ {
+		client, err := s.dial(ctx)
+		if err == nil {
+			s.mu.Lock()
+			old := s.client
+			s.client, s.gen = client, s.gen+1
+			s.mu.Unlock()
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), closeTimeout)
+				defer cancel()
+				_ = closeFn(ctx, old)
+			}()
+			return nil
+		}
+		if attempt >= policy.MaxAttempts {
+			return err
+		}
+		timer := time.NewTimer(reconnectDelay(policy, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Ping checks that the server answers a trivial query.
+func (s *Session) Ping(ctx context.Context) error {
+	a, _ := s.adapter()
+	return a.Ping(ctx)
+}
+
+// KeepAlive pings the server every interval until ctx is done and
+// reconnects when a ping fails, so a dropped connection is replaced before
+// the next query needs it. Failed reconnects are reported to onError,
+// which may be nil.
+func (s *Session) KeepAlive(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		a, gen := s.adapter()
+		if a.Ping(ctx) == nil || ctx.Err() != nil {
+			continue
+		}
+		if err := s.reconnect(ctx, gen); err != nil && onError != nil {
+			onError(err)
+		}
+	}
+}
+
+// Close closes the current connection.
+func (s *Session) Close(ctx context.Context) error {
+	return closeFn(ctx, s.Client())
+}
@@ -0,0 +1,321 @@
+package surreal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// standIn is a local stand-in for a SurrealDB server speaking the CBOR RPC
+// protocol over websocket. It answers every query with one row and can
+// drop connections, refuse dials and stop answering queries.
+type standIn struct {
+	t     *testing.T
+	srv   *httptest.Server
+	codec *surrealcbor.Codec
+
+	mu      sync.Mutex
+	conns   []*websocket.Conn
+	calls   []string
+	queries []string
+	refuse  int
+	hang    bool
+}
+
+func newStandIn(t *testing.T) *standIn {
+	t.Helper()
+	s := &standIn{t: t, codec: surrealcbor.New()}
+	upgrader := websocket.Upgrader{Subprotocols: []string{"cbor"}}
+	s.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		if s.refuse > 0 {
+			s.refuse--
+			s.mu.Unlock()
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		s.mu.Unlock()
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		s.serve(conn)
+	}))
+	t.Cleanup(func() {
+		s.drop()
+		s.srv.Close()
+	})
+	return s
+}
+
+func (s *standIn) dsn() string {
+	return "ws" + strings.TrimPrefix(s.srv.URL, "http")
+}
+
+func (s *standIn) serve(conn *websocket.Conn) {
+	var writeMu sync.Mutex
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req struct {
+			ID     any    `cbor:"id"`
+			Method string `cbor:"method"`
+			Params []any  `cbor:"params"`
+		}
+		if err := s.codec.Unmarshal(data, &req); err != nil {
+			s.t.Errorf("stand-in: decode request: %v", err)
+			return
+		}
+		s.mu.Lock()
+		s.calls = append(s.calls, req.Method)
+		hang := s.hang
+		var result any
+		switch req.Method {
+		case "signin", "signup":
+			result = "stand-in-token"
+		case "query":
+			if len(req.Params) > 0 {
+				sql, _ := req.Params[0].(string)
+				s.queries = append(s.queries, sql)
+			}
+			result = []map[string]any{{"status": "OK", "time": "1ms", "result": []map[string]any{{"ok": true}}}}
+		}
+		s.mu.Unlock()
+		if req.Method == "query" && hang {
+			continue
+		}
+		out, err := s.codec.Marshal(map[string]any{"id": req.ID, "result": result})
+		if err != nil {
+			s.t.Errorf("stand-in: encode response: %v", err)
+			return
+		}
+		writeMu.Lock()
+		err = conn.WriteMessage(websocket.BinaryMessage, out)
+		writeMu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+// drop closes every open connection with a close frame, as a restarting
+// server would.
+func (s *standIn) drop() {
+	s.mu.Lock()
+	conns := s.conns
+	s.conns = nil
+	s.mu.Unlock()
+	for _, conn := range conns {
+		msg := websocket.FormatCloseMessage(websocket.CloseServiceRestart, "restart")
+		_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		_ = conn.Close()
+	}
+	// Give the clients' read loops time to process the close frame.
+	time.Sleep(50 * time.Millisecond)
+}
+
+func (s *standIn) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, m := range s.calls {
+		if m == method {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *standIn) queryCount(prefix string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, q := range s.queries {
+		if strings.HasPrefix(q, prefix) {
+			n++
+		}
+	}
+	return n
+}
+
+func openStandIn(t *testing.T, s *standIn) *Session {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	session, err := Open(ctx, s.dsn(), "ns", "db", Credentials{Method: AuthDatabase, Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	session.ReconnectPolicy = orm.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	t.Cleanup(func() { _ = session.Close(context.Background()) })
+	return session
+}
+
+func TestSessionReconnectReplaysReads(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	exec := orm.NewExecutor(session)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := exec.Exec(ctx, qb.Select().From(qb.T("user"))); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	first := session.Client()
+	s.drop()
+
+	rows, err := exec.Exec(ctx, qb.Select().From(qb.T("user")))
+	if err != nil {
+		t.Fatalf("select after drop: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("unexpected rows after reconnect: %v", rows)
+	}
+	if session.Client() == first {
+		t.Fatalf("session kept the dropped client")
+	}
+	if got := s.count("signin"); got != 2 {
+		t.Fatalf("expected sign-in on reconnect, got %d sign-ins", got)
+	}
+	if got := s.count("use"); got != 2 {
+		t.Fatalf("expected USE on reconnect, got %d", got)
+	}
+}
+
+func TestSessionReconnectDoesNotReplayWrites(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	exec := orm.NewExecutor(session)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.drop()
+	if _, err := exec.Exec(ctx, qb.Create(qb.T("user"))); err == nil {
+		t.Fatalf("expected write on a dropped connection to fail")
+	}
+	if got := s.queryCount("CREATE"); got != 0 {
+		t.Fatalf("write was replayed %d times", got)
+	}
+	if s.count("signin") != 2 {
+		t.Fatalf("expected the session to reconnect after the failed write")
+	}
+	if _, err := exec.Exec(ctx, qb.Create(qb.T("user"))); err != nil {
+		t.Fatalf("write after reconnect: %v", err)
+	}
+}
+
+func TestSessionKeepsConnectionOnEncodingErrors(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := session.Client()
+	if _, err := session.Query(ctx, "SELECT * FROM $v", map[string]any{"v": make(chan int)}); err == nil {
+		t.Fatalf("expected the unencodable variable to fail")
+	}
+	if session.Client() != first || s.count("signin") != 1 {
+		t.Fatalf("expected no reconnect after an encoding error, got %d sign-ins", s.count("signin"))
+	}
+}
+
+func TestSessionReconnectBackoff(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s.drop()
+	s.mu.Lock()
+	s.refuse = 2
+	s.mu.Unlock()
+	if err := session.Reconnect(ctx); err != nil {
+		t.Fatalf("reconnect after two refused dials: %v", err)
+	}
+	if err := session.Ping(ctx); err != nil {
+		t.Fatalf("ping after reconnect: %v", err)
+	}
+
+	s.drop()
+	s.mu.Lock()
+	s.refuse = 3
+	s.mu.Unlock()
+	if err := session.Reconnect(ctx); err == nil {
+		t.Fatalf("expected reconnect to give up after three attempts")
+	}
+	s.mu.Lock()
+	left := s.refuse
+	s.mu.Unlock()
+	if left != 0 {
+		t.Fatalf("expected three dial attempts, %d refusals left", left)
+	}
+}
+
+func TestReconnectDelay(t *testing.T) {
+	p := orm.RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 5: 25 * time.Millisecond} {
+		if d := reconnectDelay(p, attempt); d < max/2 || d > max {
+			t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
+
+func TestSessionQueryTimeout(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	session.QueryTimeout = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := session.Ping(ctx); err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	s.mu.Lock()
+	s.hang = true
+	s.mu.Unlock()
+	_, err := session.Query(ctx, "CREATE user", nil)
+	if !errors.Is(err, orm.ErrTimeout) {
+		t.Fatalf("expected ErrTimeout, got %v", err)
+	}
+	if err := session.Ping(ctx); !errors.Is(err, orm.ErrTimeout) {
+		t.Fatalf("expected ping to time out, got %v", err)
+	}
+}
+
+func TestSessionKeepAliveReconnects(t *testing.T) {
+	s := newStandIn(t)
+	session := openStandIn(t, s)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	first := session.Client()
+	s.drop()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		session.KeepAlive(ctx, 10*time.Millisecond, nil)
+	}()
+	for session.Client() == first && ctx.Err() == nil {
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+	if session.Client() == first {
+		t.Fatalf("keep-alive did not reconnect")
+	}
+}
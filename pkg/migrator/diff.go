@@ -19,6 +19,9 @@ type DiffOptions struct {
 func DiffResources(code ResourceSet, db ResourceSet, opts DiffOptions) (up []qb.Statement, down []qb.Statement) {
 	up = []qb.Statement{}
 	down = []qb.Statement{}
+	// Table removals go last: removing a table drops its fields, indexes
+	// and events, so later REMOVE statements for them would fail.
+	var upDrops, downDrops []qb.Statement
 
 	// Tables
 	allTables := unionKeys(code.Tables, db.Tables)
@@ -28,9 +31,9 @@ func DiffResources(code ResourceSet, db ResourceSet, opts DiffOptions) (up []qb.
 		switch {
 		case hasCode && !hasDB:
 			up = append(up, codeDef.Statement)
-			down = append(down, qb.RemoveTable(table))
+			downDrops = append(downDrops, qb.RemoveTable(table))
 		case !hasCode && hasDB:
-			up = append(up, qb.RemoveTable(table))
+			upDrops = append(upDrops, qb.RemoveTable(table))
 			down = append(down, dbDef.Statement)
 		case hasCode && hasDB:
 			if buildText(codeDef.Statement) != buildText(dbDef.Statement) {
@@ -82,7 +85,7 @@ func DiffResources(code ResourceSet, db ResourceSet, opts DiffOptions) (up []qb.
 		return qb.RemoveEvent(name).OnTableName(table)
 	})...)
 
-	return up, down
+	return append(up, upDrops...), append(down, downDrops...)
 }
 
 func removeAccessStmt(def Definition) qb.Statement {
@@ -83,6 +83,22 @@ func TestDiffResourcesAccessScope(t *testing.T) {
 	}
 }
 
+func TestDiffResourcesRemovesTablesLast(t *testing.T) {
+	code := NewResourceSet()
+	code.AddTable("user", qb.DefineTableName("user"))
+	code.AddField("user", "name", qb.DefineFieldName("name", "user").Type("string"))
+
+	up, down := DiffResources(code, NewResourceSet(), DiffOptions{})
+	assertTexts(t, statementsText(up), []string{
+		"DEFINE TABLE user",
+		"DEFINE FIELD name ON TABLE user TYPE string",
+	})
+	assertTexts(t, statementsText(down), []string{
+		"REMOVE FIELD name ON TABLE user",
+		"REMOVE TABLE user",
+	})
+}
+
 func TestPickRenameStrategyForce(t *testing.T) {
 	if got := pickRenameStrategy(DiffOptions{Force: true}); got != renameStrategyDelete {
 		t.Fatalf("expected force to use delete, got %s", got)
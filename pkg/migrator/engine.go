@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/surrealdb/surrealdb.go/pkg/models"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -66,7 +67,7 @@ func (m *Migrator) Up(ctx context.Context, steps int) error {
 	for _, mig := range migs {
 		if rec, ok := applied[mig.ID]; ok {
 			if m.Config.Mode == ModeStrict && rec.Checksum != mig.Checksum {
-				return fmt.Errorf("checksum mismatch for %s", mig.ID)
+				return &ChecksumMismatchError{ID: mig.ID, Applied: rec.Checksum, Expected: mig.Checksum}
 			}
 			continue
 		}
@@ -177,7 +178,7 @@ func (m *Migrator) Generate(ctx context.Context, code ResourceSet, db ResourceSe
 		}
 	}
 	if len(up) == 0 && len(down) == 0 {
-		return "", fmt.Errorf("no changes detected")
+		return "", ErrNoChanges
 	}
 	stamp := time.Now().UTC().Format("20060102150405")
 	base := fmt.Sprintf("%s_%s", stamp, sanitizeName(name))
@@ -256,7 +257,8 @@ func (m *Migrator) rollbackMigration(ctx context.Context, mig Migration) error {
 	if _, err := m.DB.Query(ctx, mig.DownSQL, nil); err != nil {
 		return err
 	}
-	remove := qb.Delete(qb.T(migrationsTable)).Where(qb.I("id").Eq(mig.ID))
+	// The record id is _migrations:<id>, so match on the plain name field.
+	remove := qb.Delete(qb.T(migrationsTable)).Where(qb.I("name").Eq(mig.ID))
 	_, err := m.DB.Query(ctx, qb.Build(remove).Text, qb.Build(remove).Args)
 	return err
 }
@@ -333,6 +335,13 @@ func toString(v any) string {
 	switch t := v.(type) {
 	case string:
 		return t
+	case models.RecordID:
+		return toString(t.ID)
+	case *models.RecordID:
+		if t == nil {
+			return ""
+		}
+		return toString(t.ID)
 	case []byte:
 		return string(t)
 	default:
@@ -2,11 +2,13 @@ package migrator
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/surrealdb/surrealdb.go/pkg/models"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -84,8 +86,8 @@ func TestGenerateNoChanges(t *testing.T) {
 	m := New(&fakeDB{}, Config{Dir: t.TempDir()}, NoPrompter{})
 	code := NewResourceSet()
 	db := NewResourceSet()
-	if _, err := m.Generate(context.Background(), code, db, ""); err == nil {
-		t.Fatalf("expected no changes error")
+	if _, err := m.Generate(context.Background(), code, db, ""); !errors.Is(err, ErrNoChanges) {
+		t.Fatalf("expected ErrNoChanges, got %v", err)
 	}
 }
 
@@ -107,8 +109,13 @@ func TestChecksumMismatchStrict(t *testing.T) {
 	}
 
 	m := New(db, Config{Dir: dir, Mode: ModeStrict}, NoPrompter{})
-	if err := m.Up(context.Background(), 0); err == nil {
-		t.Fatalf("expected checksum mismatch error")
+	err := m.Up(context.Background(), 0)
+	var mismatch *ChecksumMismatchError
+	if !errors.Is(err, ErrChecksumMismatch) || !errors.As(err, &mismatch) {
+		t.Fatalf("expected checksum mismatch error, got %v", err)
+	}
+	if mismatch.ID != "001_init" || mismatch.Applied != badChecksum {
+		t.Fatalf("unexpected mismatch: %+v", mismatch)
 	}
 }
 
@@ -130,6 +137,84 @@ func TestDownErrors(t *testing.T) {
 	}
 }
 
+// applyMigration creates _migrations records with CONTENT { id, ... }, so
+// SurrealDB returns their id as a RecordID rather than a string.
+func TestListReadsRecordIDRows(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "001_init", "DEFINE TABLE test", "REMOVE TABLE test", true)
+	db := &fakeDB{fn: func(sql string) ([]map[string]any, error) {
+		if strings.HasPrefix(sql, "SELECT id, checksum") {
+			return []map[string]any{
+				{"id": models.RecordID{Table: migrationsTable, ID: "001_init"}, "checksum": "x", "applied_at": "now"},
+				{"id": &models.RecordID{Table: migrationsTable, ID: "002_gone"}, "checksum": "y", "applied_at": "now"},
+			}, nil
+		}
+		return nil, nil
+	}}
+
+	m := New(db, Config{Dir: dir, Mode: ModeLax, TwoWay: true}, NoPrompter{})
+	statuses, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Migration.ID != "001_init" || !statuses[0].Applied {
+		t.Fatalf("expected 001_init to be listed as applied, got %+v", statuses)
+	}
+	if got := toString(models.RecordID{Table: migrationsTable, ID: "002_gone"}); got != "002_gone" {
+		t.Fatalf("unexpected record id string: %q", got)
+	}
+}
+
+// applyMigration creates _migrations records with CONTENT { id, name, ... },
+// so SurrealDB stores them as _migrations:<id> and returns the id as a
+// RecordID; name holds the plain migration id in rows written by every
+// version of the migrator.
+func TestDownRollsBackRecordIDRows(t *testing.T) {
+	dir := t.TempDir()
+	writeMigration(t, dir, "001_init", "DEFINE TABLE test", "REMOVE TABLE test", true)
+
+	db := &fakeDB{}
+	db.fn = func(sql string) ([]map[string]any, error) {
+		if strings.HasPrefix(sql, "SELECT id, checksum") {
+			return []map[string]any{{
+				"id":         models.RecordID{Table: migrationsTable, ID: "001_init"},
+				"name":       "001_init",
+				"checksum":   "x",
+				"applied_at": "now",
+			}}, nil
+		}
+		return nil, nil
+	}
+
+	m := New(db, Config{Dir: dir, Mode: ModeLax, TwoWay: true}, NoPrompter{})
+	statuses, err := m.List(context.Background())
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(statuses) != 1 || !statuses[0].Applied {
+		t.Fatalf("expected 001_init to be listed as applied, got %+v", statuses)
+	}
+	if err := m.Down(context.Background(), 1); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	calls := db.Calls()
+	var removed, deleted bool
+	for i, sql := range calls {
+		switch {
+		case sql == "REMOVE TABLE test":
+			removed = true
+		case strings.HasPrefix(sql, "DELETE "+migrationsTable):
+			if sql != "DELETE "+migrationsTable+" WHERE name = $p1" || db.vars[i]["p1"] != "001_init" {
+				t.Fatalf("unexpected delete: %s %v", sql, db.vars[i])
+			}
+			deleted = true
+		}
+	}
+	if !removed || !deleted {
+		t.Fatalf("expected down SQL and record removal, got %v", calls)
+	}
+}
+
 func TestHelperFunctions(t *testing.T) {
 	if got := sanitizeName("My Name-Here"); got != "my_name_here" {
 		t.Fatalf("unexpected sanitize: %s", got)
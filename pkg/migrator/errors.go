@@ -1,6 +1,32 @@
 package migrator
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoChanges is returned by Generate when code and database resources
+// already match.
+var ErrNoChanges = errors.New("migrator: no changes detected")
+
+// ErrChecksumMismatch is matched by ChecksumMismatchError.
+var ErrChecksumMismatch = errors.New("migrator: checksum mismatch")
+
+// ChecksumMismatchError reports an applied migration whose file changed
+// since it was applied (strict mode only).
+type ChecksumMismatchError struct {
+	ID       string
+	Applied  string
+	Expected string
+}
+
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("migrator: checksum mismatch for %s", e.ID)
+}
+
+func (e *ChecksumMismatchError) Is(target error) bool {
+	return target == ErrChecksumMismatch
+}
 
 // NotImplemented is a temporary placeholder for scaffolded commands.
 func NotImplemented(action string) error {
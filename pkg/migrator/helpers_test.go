@@ -9,11 +9,13 @@ type fakeDB struct {
 	mu    sync.Mutex
 	fn    func(sql string) ([]map[string]any, error)
 	calls []string
+	vars  []map[string]any
 }
 
 func (f *fakeDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
 	f.mu.Lock()
 	f.calls = append(f.calls, sql)
+	f.vars = append(f.vars, vars)
 	f.mu.Unlock()
 	if f.fn != nil {
 		return f.fn(sql)
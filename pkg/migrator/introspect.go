@@ -20,6 +20,9 @@ func Introspect(ctx context.Context, db DB) (ResourceSet, error) {
 	root := rows[0]
 	tablesMap := toStringMap(root["tables"])
 	for table, val := range tablesMap {
+		if table == migrationsTable {
+			continue
+		}
 		def := extractDefinition(val)
 		if def == "" {
 			continue
@@ -57,6 +57,37 @@ func TestIntrospect(t *testing.T) {
 	}
 }
 
+func TestIntrospectSkipsMigrationsTable(t *testing.T) {
+	db := &fakeDB{}
+	db.fn = func(sql string) ([]map[string]any, error) {
+		if sql == "INFO FOR DB" {
+			return []map[string]any{{
+				"tables": map[string]any{
+					migrationsTable: "DEFINE TABLE _migrations",
+					"user":          "DEFINE TABLE user",
+				},
+			}}, nil
+		}
+		return nil, nil
+	}
+
+	res, err := Introspect(context.Background(), db)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if _, ok := res.Tables[migrationsTable]; ok {
+		t.Fatalf("expected %s to be left out so diffs never remove it", migrationsTable)
+	}
+	if _, ok := res.Tables["user"]; !ok {
+		t.Fatalf("expected user table")
+	}
+	for _, sql := range db.Calls() {
+		if strings.Contains(sql, migrationsTable) {
+			t.Fatalf("unexpected query for %s: %s", migrationsTable, sql)
+		}
+	}
+}
+
 func TestExtractDefinitionVariants(t *testing.T) {
 	if got := extractDefinition("DEF"); got != "DEF" {
 		t.Fatalf("unexpected string def")
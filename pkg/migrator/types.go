@@ -1,6 +1,9 @@
 package migrator
 
-import "io/fs"
+import (
+	"io/fs"
+	"time"
+)
 
 // Mode defines migration strictness.
 type Mode string
@@ -24,6 +27,19 @@ type Config struct {
 	RenameStrategy string
 	RenameExpr     string
 	GrantsAlways   bool
+	// Auth selects the authentication method (root, namespace, database,
+	// record or token; empty signs in with Username and Password at the
+	// level NS and DB imply). Access, AccessVars and SignUp configure
+	// record access; Token is a pre-issued JWT.
+	Auth       string
+	Access     string
+	AccessVars map[string]string
+	SignUp     bool
+	Token      string
+	// ConnectTimeout bounds establishing the connection and QueryTimeout
+	// each query; zero means no limit.
+	ConnectTimeout time.Duration
+	QueryTimeout   time.Duration
 }
 
 // Source provides access to migration files.
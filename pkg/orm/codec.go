@@ -0,0 +1,391 @@
+package orm
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+)
+
+// MapEncoder is implemented by models that render their own row payload.
+// Generated models implement it; other types fall back to JSON.
+type MapEncoder interface {
+	ToMap() map[string]any
+}
+
+// MapDecoder is implemented by model pointers that decode their own rows.
+type MapDecoder interface {
+	FromMap(row map[string]any) error
+}
+
+// EncodeModel converts a model to a row payload.
+func EncodeModel(v any) (map[string]any, error) {
+	if enc, ok := v.(MapEncoder); ok {
+		return enc.ToMap(), nil
+	}
+	if m, ok := v.(map[string]any); ok {
+		return m, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("orm: encode %T: %w", v, err)
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("orm: encode %T: %w", v, err)
+	}
+	return out, nil
+}
+
+// DecodeRow converts a result row into T.
+func DecodeRow[T any](row map[string]any) (T, error) {
+	var out T
+	if dec, ok := any(&out).(MapDecoder); ok {
+		if err := dec.FromMap(row); err != nil {
+			return out, err
+		}
+		return out, nil
+	}
+	data, err := json.Marshal(row)
+	if err != nil {
+		return out, fmt.Errorf("orm: decode %T: %w", out, err)
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return out, fmt.Errorf("orm: decode %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// DecodeRows converts result rows into a slice of T.
+func DecodeRows[T any](rows []map[string]any) ([]T, error) {
+	out := make([]T, 0, len(rows))
+	for _, row := range rows {
+		v, err := DecodeRow[T](row)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// MarshalCBORMap encodes a row payload with SurrealDB's CBOR tags. Generated
+// MarshalCBOR methods delegate here.
+func MarshalCBORMap(row map[string]any) ([]byte, error) {
+	return surrealcbor.Marshal(row)
+}
+
+// UnmarshalCBORMap decodes a CBOR row and hands it to dst.FromMap.
+// Generated UnmarshalCBOR methods delegate here.
+func UnmarshalCBORMap(data []byte, dst MapDecoder) error {
+	row := map[string]any{}
+	if err := surrealcbor.Unmarshal(data, &row); err != nil {
+		return err
+	}
+	return dst.FromMap(row)
+}
+
+// IsZero reports whether v is its type's zero value. Generated ToMap methods
+// use it to leave unset record IDs out of the payload.
+func IsZero[T comparable](v T) bool {
+	var zero T
+	return v == zero
+}
+
+// EncodeSlice converts nested objects to row payloads.
+func EncodeSlice[E MapEncoder](items []E) []map[string]any {
+	if items == nil {
+		return nil
+	}
+	out := make([]map[string]any, len(items))
+	for i, item := range items {
+		out[i] = item.ToMap()
+	}
+	return out
+}
+
+// EncodePtr converts an optional nested object to a row payload.
+func EncodePtr[E MapEncoder](v *E) any {
+	if v == nil {
+		return nil
+	}
+	return (*v).ToMap()
+}
+
+// DecodeField decodes row[key] into dst. A missing key leaves dst untouched
+// and NULL/NONE resets it to the zero value.
+func DecodeField[T any](row map[string]any, key string, dst *T) error {
+	v, ok := row[key]
+	if !ok {
+		return nil
+	}
+	if err := decodeValue(v, dst); err != nil {
+		return fmt.Errorf("orm: decode field %s: %w", key, err)
+	}
+	return nil
+}
+
+// DecodeSlice decodes row[key], an array, into dst element by element.
+func DecodeSlice[E any](row map[string]any, key string, dst *[]E) error {
+	v, ok := row[key]
+	if !ok {
+		return nil
+	}
+	switch items := v.(type) {
+	case nil:
+		*dst = nil
+		return nil
+	case []E:
+		*dst = items
+		return nil
+	case []any:
+		out := make([]E, len(items))
+		for i, item := range items {
+			if err := decodeValue(item, &out[i]); err != nil {
+				return fmt.Errorf("orm: decode field %s[%d]: %w", key, i, err)
+			}
+		}
+		*dst = out
+		return nil
+	}
+	return DecodeField(row, key, dst)
+}
+
+// DecodePtr decodes an optional row[key] into *dst.
+func DecodePtr[E any](row map[string]any, key string, dst **E) error {
+	v, ok := row[key]
+	if !ok {
+		return nil
+	}
+	if v == nil {
+		*dst = nil
+		return nil
+	}
+	out := new(E)
+	if err := decodeValue(v, out); err != nil {
+		return fmt.Errorf("orm: decode field %s: %w", key, err)
+	}
+	*dst = out
+	return nil
+}
+
+// decodeValue assigns v to dst, trying links, direct assignment, nested FromMap,
+// record IDs, text decoding and numeric conversion before falling back to a
+// CBOR round trip.
+func decodeValue[T any](v any, dst *T) error {
+	if l, ok := any(dst).(linkDecoder); ok {
+		return l.decodeLink(v)
+	}
+	if v == nil {
+		var zero T
+		*dst = zero
+		return nil
+	}
+	if t, ok := v.(T); ok {
+		*dst = t
+		return nil
+	}
+	if dec, ok := any(dst).(MapDecoder); ok {
+		if row, ok := v.(map[string]any); ok {
+			return dec.FromMap(row)
+		}
+	}
+	if setter, ok := any(dst).(recordIDSetter); ok {
+		switch rid := v.(type) {
+		case models.RecordID:
+			return setter.setRecordID(rid.Table, rid.ID)
+		case *models.RecordID:
+			return setter.setRecordID(rid.Table, rid.ID)
+		}
+	}
+	if text, ok := v.(string); ok {
+		if u, ok := any(dst).(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(text))
+		}
+	}
+	if ok, err := assignScalar(v, dst); ok || err != nil {
+		return err
+	}
+	data, err := surrealcbor.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return surrealcbor.Unmarshal(data, dst)
+}
+
+// assignScalar converts between the numeric types the driver returns and the
+// field type, and renders record IDs into string fields. It reports false if
+// it does not handle the pair, and an error for a number that is fractional
+// or out of range for an integer field.
+func assignScalar(v any, dst any) (bool, error) {
+	if s, ok := dst.(*string); ok {
+		switch id := v.(type) {
+		case models.RecordID:
+			*s = id.String()
+		case fmt.Stringer:
+			*s = id.String()
+		default:
+			return false, nil
+		}
+		return true, nil
+	}
+	var n number
+	switch x := v.(type) {
+	case int:
+		n = number{v: v, i: int64(x)}
+	case int8:
+		n = number{v: v, i: int64(x)}
+	case int16:
+		n = number{v: v, i: int64(x)}
+	case int32:
+		n = number{v: v, i: int64(x)}
+	case int64:
+		n = number{v: v, i: x}
+	case uint:
+		n = number{v: v, kind: numUint, u: uint64(x)}
+	case uint8:
+		n = number{v: v, kind: numUint, u: uint64(x)}
+	case uint16:
+		n = number{v: v, kind: numUint, u: uint64(x)}
+	case uint32:
+		n = number{v: v, kind: numUint, u: uint64(x)}
+	case uint64:
+		n = number{v: v, kind: numUint, u: x}
+	case float32:
+		n = number{v: v, kind: numFloat, f: float64(x)}
+	case float64:
+		n = number{v: v, kind: numFloat, f: x}
+	default:
+		return false, nil
+	}
+	switch d := dst.(type) {
+	case *int:
+		return true, setInt(d, n, strconv.IntSize)
+	case *int8:
+		return true, setInt(d, n, 8)
+	case *int16:
+		return true, setInt(d, n, 16)
+	case *int32:
+		return true, setInt(d, n, 32)
+	case *int64:
+		return true, setInt(d, n, 64)
+	case *uint:
+		return true, setUint(d, n, strconv.IntSize)
+	case *uint8:
+		return true, setUint(d, n, 8)
+	case *uint16:
+		return true, setUint(d, n, 16)
+	case *uint32:
+		return true, setUint(d, n, 32)
+	case *uint64:
+		return true, setUint(d, n, 64)
+	case *float32:
+		*d = float32(n.float())
+	case *float64:
+		*d = n.float()
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+const (
+	numInt = iota
+	numUint
+	numFloat
+)
+
+// number is a numeric driver value held in the widest type of its kind.
+type number struct {
+	v    any
+	kind int
+	i    int64
+	u    uint64
+	f    float64
+}
+
+func (n number) float() float64 {
+	switch n.kind {
+	case numUint:
+		return float64(n.u)
+	case numFloat:
+		return n.f
+	}
+	return float64(n.i)
+}
+
+func (n number) int(bits int) (int64, error) {
+	var i int64
+	switch n.kind {
+	case numUint:
+		if n.u > math.MaxInt64 {
+			return 0, n.overflows("int", bits)
+		}
+		i = int64(n.u)
+	case numFloat:
+		if n.f != math.Trunc(n.f) {
+			return 0, fmt.Errorf("%v is not a whole number", n.v)
+		}
+		if n.f < math.MinInt64 || n.f >= math.MaxInt64 {
+			return 0, n.overflows("int", bits)
+		}
+		i = int64(n.f)
+	default:
+		i = n.i
+	}
+	if bits < 64 && (i < -1<<(bits-1) || i >= 1<<(bits-1)) {
+		return 0, n.overflows("int", bits)
+	}
+	return i, nil
+}
+
+func (n number) uint(bits int) (uint64, error) {
+	var u uint64
+	switch n.kind {
+	case numUint:
+		u = n.u
+	case numFloat:
+		if n.f != math.Trunc(n.f) {
+			return 0, fmt.Errorf("%v is not a whole number", n.v)
+		}
+		if n.f < 0 || n.f >= math.MaxUint64 {
+			return 0, n.overflows("uint", bits)
+		}
+		u = uint64(n.f)
+	default:
+		if n.i < 0 {
+			return 0, n.overflows("uint", bits)
+		}
+		u = uint64(n.i)
+	}
+	if bits < 64 && u >= 1<<bits {
+		return 0, n.overflows("uint", bits)
+	}
+	return u, nil
+}
+
+func (n number) overflows(kind string, bits int) error {
+	return fmt.Errorf("%v overflows %s%d", n.v, kind, bits)
+}
+
+func setInt[T int | int8 | int16 | int32 | int64](dst *T, n number, bits int) error {
+	i, err := n.int(bits)
+	if err != nil {
+		return err
+	}
+	*dst = T(i)
+	return nil
+}
+
+func setUint[T uint | uint8 | uint16 | uint32 | uint64](dst *T, n number, bits int) error {
+	u, err := n.uint(bits)
+	if err != nil {
+		return err
+	}
+	*dst = T(u)
+	return nil
+}
@@ -0,0 +1,170 @@
+package orm
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+type codecAddress struct {
+	City string
+}
+
+func (a codecAddress) ToMap() map[string]any {
+	return map[string]any{"city": a.City}
+}
+
+func (a *codecAddress) FromMap(row map[string]any) error {
+	return DecodeField(row, "city", &a.City)
+}
+
+type codecUser struct {
+	ID    string
+	Age   int
+	Score float32
+	Home  *codecAddress
+	Past  []codecAddress
+}
+
+func (u codecUser) ToMap() map[string]any {
+	out := map[string]any{"age": u.Age, "score": u.Score, "home": EncodePtr(u.Home), "past": EncodeSlice(u.Past)}
+	if !IsZero(u.ID) {
+		out["id"] = u.ID
+	}
+	return out
+}
+
+func (u *codecUser) FromMap(row map[string]any) error {
+	if err := DecodeField(row, "id", &u.ID); err != nil {
+		return err
+	}
+	if err := DecodeField(row, "age", &u.Age); err != nil {
+		return err
+	}
+	if err := DecodeField(row, "score", &u.Score); err != nil {
+		return err
+	}
+	if err := DecodePtr(row, "home", &u.Home); err != nil {
+		return err
+	}
+	return DecodeSlice(row, "past", &u.Past)
+}
+
+func TestDecodeFieldsFromDriverRow(t *testing.T) {
+	row := map[string]any{
+		"id":    models.NewRecordID("user", "ann"),
+		"age":   uint64(42),
+		"score": 1.5,
+		"home":  map[string]any{"city": "Oslo"},
+		"past":  []any{map[string]any{"city": "Bergen"}},
+	}
+	got, err := DecodeRow[codecUser](row)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := codecUser{ID: "user:ann", Age: 42, Score: 1.5, Home: &codecAddress{City: "Oslo"}, Past: []codecAddress{{City: "Bergen"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected decode: %+v", got)
+	}
+}
+
+func TestDecodeFieldErrorNamesField(t *testing.T) {
+	var u codecUser
+	err := u.FromMap(map[string]any{"age": "old"})
+	if err == nil || !strings.HasPrefix(err.Error(), "orm: decode field age") {
+		t.Fatalf("expected field in error, got %v", err)
+	}
+}
+
+func TestDecodeFieldChecksNumbers(t *testing.T) {
+	var (
+		i   int
+		i8  int8
+		u   uint
+		u16 uint16
+		f   float64
+	)
+	ok := []struct {
+		row map[string]any
+		dst any
+	}{
+		{map[string]any{"n": 3.0}, &i},
+		{map[string]any{"n": int64(-128)}, &i8},
+		{map[string]any{"n": uint64(math.MaxInt64)}, &i},
+		{map[string]any{"n": uint64(math.MaxUint64)}, &u},
+		{map[string]any{"n": int64(65535)}, &u16},
+		{map[string]any{"n": uint64(7)}, &f},
+	}
+	for _, tc := range ok {
+		if err := decodeInto(tc.row, tc.dst); err != nil {
+			t.Fatalf("decode %v into %T: %v", tc.row["n"], tc.dst, err)
+		}
+	}
+	if i != math.MaxInt64 || i8 != -128 || u != math.MaxUint64 || u16 != 65535 || f != 7 {
+		t.Fatalf("unexpected values: %d %d %d %d %v", i, i8, u, u16, f)
+	}
+
+	bad := []struct {
+		row map[string]any
+		dst any
+		msg string
+	}{
+		{map[string]any{"n": 1.5}, &i, "not a whole number"},
+		{map[string]any{"n": math.NaN()}, &i, "not a whole number"},
+		{map[string]any{"n": uint64(math.MaxUint64)}, &i, "overflows int64"},
+		{map[string]any{"n": 1e19}, &i, "overflows int64"},
+		{map[string]any{"n": int64(128)}, &i8, "overflows int8"},
+		{map[string]any{"n": int64(-1)}, &u, "overflows uint64"},
+		{map[string]any{"n": -1.0}, &u, "overflows uint64"},
+		{map[string]any{"n": int64(65536)}, &u16, "overflows uint16"},
+	}
+	for _, tc := range bad {
+		err := decodeInto(tc.row, tc.dst)
+		if err == nil || !strings.Contains(err.Error(), tc.msg) || !strings.HasPrefix(err.Error(), "orm: decode field n") {
+			t.Fatalf("decode %v into %T: expected %q error, got %v", tc.row["n"], tc.dst, tc.msg, err)
+		}
+	}
+}
+
+func decodeInto(row map[string]any, dst any) error {
+	switch d := dst.(type) {
+	case *int:
+		return DecodeField(row, "n", d)
+	case *int8:
+		return DecodeField(row, "n", d)
+	case *uint:
+		return DecodeField(row, "n", d)
+	case *uint16:
+		return DecodeField(row, "n", d)
+	case *float64:
+		return DecodeField(row, "n", d)
+	}
+	panic("unexpected destination")
+}
+
+func TestCBORMapRoundTrip(t *testing.T) {
+	in := codecUser{ID: "user:1", Age: 3, Home: &codecAddress{City: "Rome"}}
+	data, err := MarshalCBORMap(in.ToMap())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out codecUser
+	if err := UnmarshalCBORMap(data, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("unexpected round trip: %+v", out)
+	}
+}
+
+func TestEncodeOmitsZeroID(t *testing.T) {
+	if _, ok := (codecUser{}).ToMap()["id"]; ok {
+		t.Fatalf("expected zero id to be omitted")
+	}
+	if EncodePtr[codecAddress](nil) != nil {
+		t.Fatalf("expected nil for nil pointer")
+	}
+}
@@ -0,0 +1,148 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+)
+
+// Sentinels for classified database failures. ClassifyError wraps driver
+// errors in a *DBError matching one of them (or ErrNotFound) with errors.Is.
+var (
+	ErrUniqueViolation     = errors.New("orm: unique constraint violation")
+	ErrAssertion           = errors.New("orm: assertion failed")
+	ErrPermissionDenied    = errors.New("orm: permission denied")
+	ErrTransactionConflict = errors.New("orm: transaction conflict")
+	ErrParse               = errors.New("orm: parse error")
+	ErrTimeout             = errors.New("orm: query timeout")
+	// ErrTransactionFailed marks statements skipped because another
+	// statement of their transaction failed.
+	ErrTransactionFailed = errors.New("orm: transaction failed")
+)
+
+// DBError is a classified database error. errors.Is matches Kind, and the
+// original driver error stays reachable through Unwrap.
+type DBError struct {
+	Kind    error
+	Message string
+	Err     error
+}
+
+func (e *DBError) Error() string {
+	return e.Message
+}
+
+func (e *DBError) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *DBError) Unwrap() error {
+	return e.Err
+}
+
+// errorPatterns match SurrealDB error messages, case-insensitively and
+// wherever they appear, since RPC errors prefix them. Each pattern anchors
+// on the server's full phrasing so that unrelated messages mentioning
+// "conflict" or "not found" stay unclassified. The first match wins.
+var errorPatterns = []struct {
+	re   *regexp.Regexp
+	kind error
+}{
+	{regexp.MustCompile(`(?i)not executed due to a (failed|cancelled) transaction`), ErrTransactionFailed},
+	{regexp.MustCompile(`(?i)\bparse error:`), ErrParse},
+	{regexp.MustCompile("(?i)database index `[^`]*` already contains"), ErrUniqueViolation},
+	{regexp.MustCompile("(?i)database record `[^`]*` already exists"), ErrUniqueViolation},
+	{regexp.MustCompile("(?i)for field `[^`]*`.*but field must conform to"), ErrAssertion},
+	{regexp.MustCompile(`(?i)iam error: not enough permissions`), ErrPermissionDenied},
+	{regexp.MustCompile(`(?i)read or write conflict|this transaction can be retried`), ErrTransactionConflict},
+	{regexp.MustCompile(`(?i)not executed because it exceeded the timeout`), ErrTimeout},
+	{regexp.MustCompile(`(?i)the (record|table|namespace|database) '[^']*' does not exist`), ErrNotFound},
+}
+
+// ClassifyError wraps err in a *DBError when its message or cause matches
+// a known failure; other errors, and errors already classified, are
+// returned unchanged. A joined error, as the driver returns for a failed
+// multi-statement query, is classified by the statement that caused the
+// failure rather than by those skipped because of it.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(interface{ Unwrap() []error }); ok {
+		return classifyJoined(err)
+	}
+	var classified *DBError
+	if errors.As(err, &classified) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &DBError{Kind: ErrTimeout, Message: err.Error(), Err: err}
+	}
+	msg := err.Error()
+	for _, p := range errorPatterns {
+		if p.re.MatchString(msg) {
+			return &DBError{Kind: p.kind, Message: msg, Err: err}
+		}
+	}
+	return err
+}
+
+// classifyJoined classifies each error joined in err and returns the first
+// classified one that is not ErrTransactionFailed, falling back to the
+// first classified one. The result wraps err itself.
+func classifyJoined(err error) error {
+	var found *DBError
+	for _, e := range leafErrors(err) {
+		var c *DBError
+		if !errors.As(ClassifyError(e), &c) {
+			continue
+		}
+		if found == nil || errors.Is(found, ErrTransactionFailed) && !errors.Is(c, ErrTransactionFailed) {
+			found = c
+		}
+	}
+	if found == nil {
+		return err
+	}
+	return &DBError{Kind: found.Kind, Message: found.Message, Err: err}
+}
+
+// leafErrors flattens nested joined errors in order.
+func leafErrors(err error) []error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+	var out []error
+	for _, e := range joined.Unwrap() {
+		out = append(out, leafErrors(e)...)
+	}
+	return out
+}
+
+// driverClosed matches the errors the driver's websocket connection returns,
+// unwrapped, once its socket is gone.
+var driverClosed = regexp.MustCompile(`^(connection is closed|response channel closed|websocket: close\b.*)$`)
+
+// IsConnectionError reports whether err means the connection to the server
+// failed rather than the query: a network error, EOF, a closed socket or
+// pipe, or the driver reporting its connection closed. Errors the server
+// answered with, and local ones such as encoding failures, are not.
+func IsConnectionError(err error) bool {
+	var netErr net.Error
+	switch {
+	case err == nil:
+		return false
+	case errors.As(err, &netErr), errors.Is(err, net.ErrClosed),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, io.ErrClosedPipe):
+		return true
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		if driverClosed.MatchString(e.Error()) {
+			return true
+		}
+	}
+	return false
+}
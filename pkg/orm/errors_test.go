@@ -0,0 +1,93 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		msg  string
+		kind error
+	}{
+		{"Database index `email` already contains 'a@b.c', with record `user:1`", ErrUniqueViolation},
+		{"Database record `user:1` already exists", ErrUniqueViolation},
+		{"Found -1 for field `age`, with record `user:1`, but field must conform to: $value > 0", ErrAssertion},
+		{"IAM error: Not enough permissions to perform this action", ErrPermissionDenied},
+		{"Failed to commit transaction due to a read or write conflict. This transaction can be retried", ErrTransactionConflict},
+		{"Parse error: Unexpected token `FORM`", ErrParse},
+		{"The query was not executed because it exceeded the timeout", ErrTimeout},
+		{"The record 'user:1' does not exist", ErrNotFound},
+		{"The query was not executed due to a failed transaction", ErrTransactionFailed},
+		{"There was a problem with the database: Database record `user:1` already exists", ErrUniqueViolation},
+	}
+	for _, tc := range cases {
+		driverErr := errors.New(tc.msg)
+		err := ClassifyError(driverErr)
+		if !errors.Is(err, tc.kind) {
+			t.Fatalf("%q: expected %v, got %v", tc.msg, tc.kind, err)
+		}
+		var dbErr *DBError
+		if !errors.As(err, &dbErr) || dbErr.Message != tc.msg || !errors.Is(err, driverErr) {
+			t.Fatalf("%q: unexpected classified error %#v", tc.msg, err)
+		}
+	}
+}
+
+func TestClassifyErrorIgnoresLookalikes(t *testing.T) {
+	for _, msg := range []string{
+		"An error occurred: user not found in cache",
+		"Thrown error: config file does not exist",
+		"Thrown error: edit conflict with draft 3",
+		"Thrown error: the session already exists",
+		"dial tcp: i/o timeout",
+	} {
+		err := errors.New(msg)
+		if got := ClassifyError(err); got != err {
+			t.Fatalf("%q: expected no classification, got %#v", msg, got)
+		}
+	}
+}
+
+func TestClassifyErrorPrefersCause(t *testing.T) {
+	failed := errors.New("The query was not executed due to a failed transaction")
+	unique := errors.New("Database record `user:1` already exists")
+	// The driver joins statement errors one at a time, nesting the joins.
+	joined := errors.Join(errors.Join(errors.Join(nil, failed), unique), failed)
+
+	err := ClassifyError(joined)
+	if !errors.Is(err, ErrUniqueViolation) || errors.Is(err, ErrTransactionFailed) {
+		t.Fatalf("expected the causal unique violation, got %v", err)
+	}
+	var dbErr *DBError
+	if !errors.As(err, &dbErr) || dbErr.Message != unique.Error() || !errors.Is(err, failed) {
+		t.Fatalf("unexpected classified error %#v", err)
+	}
+
+	if err := ClassifyError(errors.Join(failed, failed)); !errors.Is(err, ErrTransactionFailed) {
+		t.Fatalf("expected ErrTransactionFailed without a cause, got %v", err)
+	}
+	other := errors.Join(errors.New("boom"))
+	if ClassifyError(other) != other {
+		t.Fatalf("expected unclassified joined error to pass through")
+	}
+}
+
+func TestClassifyErrorPassThrough(t *testing.T) {
+	if ClassifyError(nil) != nil {
+		t.Fatalf("expected nil")
+	}
+	other := errors.New("connection reset")
+	if ClassifyError(other) != other {
+		t.Fatalf("expected unknown error to pass through")
+	}
+	timeout := ClassifyError(fmt.Errorf("query: %w", context.DeadlineExceeded))
+	if !errors.Is(timeout, ErrTimeout) || !errors.Is(timeout, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline to classify as timeout, got %v", timeout)
+	}
+	if ClassifyError(timeout) != timeout {
+		t.Fatalf("expected classified error to be returned unchanged")
+	}
+}
@@ -0,0 +1,143 @@
+package orm
+
+import (
+	"context"
+	"errors"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// DB executes rendered SurrealQL. It matches migrator.DB, so surreal.Adapter
+// and migration test doubles can be used directly.
+type DB interface {
+	Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error)
+}
+
+// ErrBatchUnsupported is returned by Executor.ExecBatch when the DB cannot
+// report results per statement.
+var ErrBatchUnsupported = errors.New("orm: db does not support batches")
+
+// StatementResult is the outcome of one statement of a multi-statement
+// query. Status and Time are reported by the server ("OK" or "ERR", and
+// the execution time such as "1.2ms") and may be empty for other DBs.
+type StatementResult struct {
+	Rows   []map[string]any
+	Status string
+	Time   string
+	Err    error
+}
+
+// DecodeResult decodes the rows of one statement result into T, running
+// AfterLoad hooks, or returns the statement's error if it failed.
+func DecodeResult[T any](ctx context.Context, r StatementResult) ([]T, error) {
+	if r.Err != nil {
+		return nil, r.Err
+	}
+	return loadRows[T](ctx, r.Rows)
+}
+
+// BatchDB is implemented by DBs that return one result per statement
+// instead of concatenated rows. surreal.Adapter implements it.
+type BatchDB interface {
+	QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]StatementResult, error)
+}
+
+// Rewriter transforms a statement before it is rendered and executed.
+type Rewriter func(ctx context.Context, stmt qb.Statement) (qb.Statement, error)
+
+// Executor renders qb statements and runs them against a DB.
+type Executor struct {
+	DB           DB
+	Rewriters    []Rewriter
+	Interceptors []Interceptor
+	Retry        RetryPolicy
+}
+
+// ExecutorOption configures an Executor.
+type ExecutorOption func(*Executor)
+
+// WithRewriter appends a statement rewriter.
+func WithRewriter(r Rewriter) ExecutorOption {
+	return func(e *Executor) {
+		e.Rewriters = append(e.Rewriters, r)
+	}
+}
+
+func NewExecutor(db DB, opts ...ExecutorOption) *Executor {
+	e := &Executor{DB: db}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Prepare applies the rewriters and renders the statement.
+func (e *Executor) Prepare(ctx context.Context, stmt qb.Statement) (qb.Statement, qb.Query, error) {
+	stmt, err := e.rewrite(ctx, stmt)
+	if err != nil {
+		return nil, qb.Query{}, err
+	}
+	return stmt, qb.Build(stmt), nil
+}
+
+func (e *Executor) rewrite(ctx context.Context, stmt qb.Statement) (qb.Statement, error) {
+	for _, rw := range e.Rewriters {
+		next, err := rw(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		stmt = next
+	}
+	return stmt, nil
+}
+
+// Exec rewrites, renders and runs a statement, returning the result rows.
+// Failures are retried according to the executor's RetryPolicy.
+func (e *Executor) Exec(ctx context.Context, stmt qb.Statement) ([]map[string]any, error) {
+	stmt, q, err := e.Prepare(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withStatement(ctx, stmt)
+	var rows []map[string]any
+	err = e.Retry.run(ctx, stmt, func() error {
+		return intercept(ctx, e.Interceptors, &QueryInfo{Statement: stmt, Text: q.Text, Args: q.Args}, func(ctx context.Context) (int, error) {
+			var err error
+			rows, err = e.DB.Query(ctx, q.Text, q.Args)
+			return len(rows), err
+		})
+	})
+	return rows, err
+}
+
+// ExecBatch rewrites each statement, renders them as one chain with shared
+// parameter numbering and runs it in a single round trip. The DB must
+// implement BatchDB.
+func (e *Executor) ExecBatch(ctx context.Context, stmts ...qb.Statement) ([]StatementResult, error) {
+	db, ok := e.DB.(BatchDB)
+	if !ok {
+		return nil, ErrBatchUnsupported
+	}
+	chain := qb.QueryChain()
+	for _, stmt := range stmts {
+		next, err := e.rewrite(ctx, stmt)
+		if err != nil {
+			return nil, err
+		}
+		chain.Statements = append(chain.Statements, next)
+	}
+	q := qb.Build(chain)
+	ctx = withStatement(ctx, chain)
+	var results []StatementResult
+	err := e.Retry.run(ctx, chain, func() error {
+		return intercept(ctx, e.Interceptors, &QueryInfo{Statement: chain, Text: q.Text, Args: q.Args}, func(ctx context.Context) (int, error) {
+			var err error
+			results, err = db.QueryBatch(ctx, q.Text, q.Args)
+			return batchOutcome(results, err)
+		})
+	})
+	if results != nil {
+		return results, nil
+	}
+	return nil, err
+}
@@ -0,0 +1,36 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+func TestExecBatchPerStatementResults(t *testing.T) {
+	boom := errors.New("boom")
+	db := &batchDB{results: []StatementResult{
+		{Status: "OK", Time: "1ms", Rows: []map[string]any{{"id": "note:1", "title": "a"}}},
+		{Status: "ERR", Err: boom},
+	}}
+	exec := NewExecutor(db)
+	ctx := context.Background()
+	results, err := exec.ExecBatch(ctx,
+		qb.Select().From(qb.T("note")).Where(qb.I("title").Eq("a")),
+		qb.Select().From(qb.T("note")).Where(qb.I("title").Eq("b")),
+	)
+	if err != nil {
+		t.Fatalf("exec batch: %v", err)
+	}
+	if got := db.sql[0]; got != "SELECT * FROM note WHERE title = $p1; SELECT * FROM note WHERE title = $p2" {
+		t.Fatalf("unexpected chain: %s", got)
+	}
+	notes, err := DecodeResult[note](ctx, results[0])
+	if err != nil || len(notes) != 1 || notes[0].Title != "a" || results[0].Time != "1ms" {
+		t.Fatalf("unexpected first result: %+v %v", notes, err)
+	}
+	if _, err := DecodeResult[note](ctx, results[1]); !errors.Is(err, boom) || results[1].Status != "ERR" {
+		t.Fatalf("expected statement error, got %v", err)
+	}
+}
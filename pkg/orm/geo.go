@@ -0,0 +1,417 @@
+package orm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBOR tags used by SurrealDB for geometry values.
+const (
+	tagGeometryPoint        uint64 = 88
+	tagGeometryLine         uint64 = 89
+	tagGeometryPolygon      uint64 = 90
+	tagGeometryMultiPoint   uint64 = 91
+	tagGeometryMultiLine    uint64 = 92
+	tagGeometryMultiPolygon uint64 = 93
+	tagGeometryCollection   uint64 = 94
+)
+
+// Geometry is implemented by all SurrealDB geometry values.
+type Geometry interface {
+	// GeometryKind returns the SurrealQL geometry kind (point, line, polygon, ...).
+	GeometryKind() string
+	cborTag() cbor.Tag
+	geoJSON() geoJSON
+}
+
+// GeoPoint is a geometry<point> value.
+type GeoPoint struct {
+	Longitude float64
+	Latitude  float64
+}
+
+// GeoLine is a geometry<line> value.
+type GeoLine []GeoPoint
+
+// GeoPolygon is a geometry<polygon> value; the first ring is the exterior.
+type GeoPolygon []GeoLine
+
+// GeoMultiPoint is a geometry<multipoint> value.
+type GeoMultiPoint []GeoPoint
+
+// GeoMultiLine is a geometry<multiline> value.
+type GeoMultiLine []GeoLine
+
+// GeoMultiPolygon is a geometry<multipolygon> value.
+type GeoMultiPolygon []GeoPolygon
+
+// GeoCollection is a geometry<collection> value.
+type GeoCollection []Geometry
+
+// NewGeoPoint builds a point from longitude and latitude.
+func NewGeoPoint(lon, lat float64) GeoPoint {
+	return GeoPoint{Longitude: lon, Latitude: lat}
+}
+
+func (GeoPoint) GeometryKind() string        { return "point" }
+func (GeoLine) GeometryKind() string         { return "line" }
+func (GeoPolygon) GeometryKind() string      { return "polygon" }
+func (GeoMultiPoint) GeometryKind() string   { return "multipoint" }
+func (GeoMultiLine) GeometryKind() string    { return "multiline" }
+func (GeoMultiPolygon) GeometryKind() string { return "multipolygon" }
+func (GeoCollection) GeometryKind() string   { return "collection" }
+
+func (p GeoPoint) coords() [2]float64 {
+	return [2]float64{p.Longitude, p.Latitude}
+}
+
+func (p GeoPoint) cborTag() cbor.Tag {
+	return cbor.Tag{Number: tagGeometryPoint, Content: p.coords()}
+}
+
+func (l GeoLine) cborTag() cbor.Tag {
+	return cbor.Tag{Number: tagGeometryLine, Content: pointTags(l)}
+}
+
+func (p GeoPolygon) cborTag() cbor.Tag {
+	return cbor.Tag{Number: tagGeometryPolygon, Content: lineTags(p)}
+}
+
+func (m GeoMultiPoint) cborTag() cbor.Tag {
+	return cbor.Tag{Number: tagGeometryMultiPoint, Content: pointTags(m)}
+}
+
+func (m GeoMultiLine) cborTag() cbor.Tag {
+	return cbor.Tag{Number: tagGeometryMultiLine, Content: lineTags(m)}
+}
+
+func (m GeoMultiPolygon) cborTag() cbor.Tag {
+	items := make([]cbor.Tag, 0, len(m))
+	for _, p := range m {
+		items = append(items, p.cborTag())
+	}
+	return cbor.Tag{Number: tagGeometryMultiPolygon, Content: items}
+}
+
+func (c GeoCollection) cborTag() cbor.Tag {
+	items := make([]cbor.Tag, 0, len(c))
+	for _, g := range c {
+		items = append(items, g.cborTag())
+	}
+	return cbor.Tag{Number: tagGeometryCollection, Content: items}
+}
+
+func pointTags(points []GeoPoint) []cbor.Tag {
+	out := make([]cbor.Tag, 0, len(points))
+	for _, p := range points {
+		out = append(out, p.cborTag())
+	}
+	return out
+}
+
+func lineTags(lines []GeoLine) []cbor.Tag {
+	out := make([]cbor.Tag, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, l.cborTag())
+	}
+	return out
+}
+
+func (p GeoPoint) MarshalCBOR() ([]byte, error)        { return cbor.Marshal(p.cborTag()) }
+func (l GeoLine) MarshalCBOR() ([]byte, error)         { return cbor.Marshal(l.cborTag()) }
+func (p GeoPolygon) MarshalCBOR() ([]byte, error)      { return cbor.Marshal(p.cborTag()) }
+func (m GeoMultiPoint) MarshalCBOR() ([]byte, error)   { return cbor.Marshal(m.cborTag()) }
+func (m GeoMultiLine) MarshalCBOR() ([]byte, error)    { return cbor.Marshal(m.cborTag()) }
+func (m GeoMultiPolygon) MarshalCBOR() ([]byte, error) { return cbor.Marshal(m.cborTag()) }
+func (c GeoCollection) MarshalCBOR() ([]byte, error)   { return cbor.Marshal(c.cborTag()) }
+
+func (p *GeoPoint) UnmarshalCBOR(data []byte) error {
+	content, err := geometryContent(data, tagGeometryPoint)
+	if err != nil {
+		return err
+	}
+	var lonlat [2]float64
+	if err := cbor.Unmarshal(content, &lonlat); err != nil {
+		return fmt.Errorf("geometry point: %w", err)
+	}
+	p.Longitude, p.Latitude = lonlat[0], lonlat[1]
+	return nil
+}
+
+func (l *GeoLine) UnmarshalCBOR(data []byte) error {
+	return unmarshalGeometryItems(data, tagGeometryLine, (*[]GeoPoint)(l))
+}
+
+func (p *GeoPolygon) UnmarshalCBOR(data []byte) error {
+	return unmarshalGeometryItems(data, tagGeometryPolygon, (*[]GeoLine)(p))
+}
+
+func (m *GeoMultiPoint) UnmarshalCBOR(data []byte) error {
+	return unmarshalGeometryItems(data, tagGeometryMultiPoint, (*[]GeoPoint)(m))
+}
+
+func (m *GeoMultiLine) UnmarshalCBOR(data []byte) error {
+	return unmarshalGeometryItems(data, tagGeometryMultiLine, (*[]GeoLine)(m))
+}
+
+func (m *GeoMultiPolygon) UnmarshalCBOR(data []byte) error {
+	return unmarshalGeometryItems(data, tagGeometryMultiPolygon, (*[]GeoPolygon)(m))
+}
+
+func (c *GeoCollection) UnmarshalCBOR(data []byte) error {
+	var raws []cbor.RawMessage
+	if err := unmarshalGeometryItems(data, tagGeometryCollection, &raws); err != nil {
+		return err
+	}
+	out := make(GeoCollection, 0, len(raws))
+	for _, raw := range raws {
+		g, err := DecodeGeometryCBOR(raw)
+		if err != nil {
+			return err
+		}
+		out = append(out, g)
+	}
+	*c = out
+	return nil
+}
+
+// DecodeGeometryCBOR decodes any tagged SurrealDB geometry value.
+func DecodeGeometryCBOR(data []byte) (Geometry, error) {
+	var raw cbor.RawTag
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("geometry: %w", err)
+	}
+	var g interface {
+		Geometry
+		cbor.Unmarshaler
+	}
+	switch raw.Number {
+	case tagGeometryPoint:
+		g = &GeoPoint{}
+	case tagGeometryLine:
+		g = &GeoLine{}
+	case tagGeometryPolygon:
+		g = &GeoPolygon{}
+	case tagGeometryMultiPoint:
+		g = &GeoMultiPoint{}
+	case tagGeometryMultiLine:
+		g = &GeoMultiLine{}
+	case tagGeometryMultiPolygon:
+		g = &GeoMultiPolygon{}
+	case tagGeometryCollection:
+		g = &GeoCollection{}
+	default:
+		return nil, fmt.Errorf("geometry: unexpected tag %d", raw.Number)
+	}
+	if err := g.UnmarshalCBOR(data); err != nil {
+		return nil, err
+	}
+	return derefGeometry(g), nil
+}
+
+func derefGeometry(g Geometry) Geometry {
+	switch t := g.(type) {
+	case *GeoPoint:
+		return *t
+	case *GeoLine:
+		return *t
+	case *GeoPolygon:
+		return *t
+	case *GeoMultiPoint:
+		return *t
+	case *GeoMultiLine:
+		return *t
+	case *GeoMultiPolygon:
+		return *t
+	case *GeoCollection:
+		return *t
+	}
+	return g
+}
+
+func geometryContent(data []byte, want uint64) ([]byte, error) {
+	var raw cbor.RawTag
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("geometry: %w", err)
+	}
+	if raw.Number != want {
+		return nil, fmt.Errorf("geometry: unexpected tag %d, want %d", raw.Number, want)
+	}
+	return raw.Content, nil
+}
+
+func unmarshalGeometryItems(data []byte, tag uint64, out any) error {
+	content, err := geometryContent(data, tag)
+	if err != nil {
+		return err
+	}
+	if err := cbor.Unmarshal(content, out); err != nil {
+		return fmt.Errorf("geometry: %w", err)
+	}
+	return nil
+}
+
+// geoJSON is the GeoJSON geometry object used for JSON encoding.
+type geoJSON struct {
+	Type        string    `json:"type"`
+	Coordinates any       `json:"coordinates,omitempty"`
+	Geometries  []geoJSON `json:"geometries,omitempty"`
+}
+
+func (p GeoPoint) geoJSON() geoJSON {
+	return geoJSON{Type: "Point", Coordinates: p.coords()}
+}
+
+func (l GeoLine) geoJSON() geoJSON {
+	return geoJSON{Type: "LineString", Coordinates: lineCoords(l)}
+}
+
+func (p GeoPolygon) geoJSON() geoJSON {
+	return geoJSON{Type: "Polygon", Coordinates: polygonCoords(p)}
+}
+
+func (m GeoMultiPoint) geoJSON() geoJSON {
+	return geoJSON{Type: "MultiPoint", Coordinates: lineCoords(m)}
+}
+
+func (m GeoMultiLine) geoJSON() geoJSON {
+	return geoJSON{Type: "MultiLineString", Coordinates: polygonCoords(m)}
+}
+
+func (m GeoMultiPolygon) geoJSON() geoJSON {
+	coords := make([][][][2]float64, 0, len(m))
+	for _, p := range m {
+		coords = append(coords, polygonCoords(p))
+	}
+	return geoJSON{Type: "MultiPolygon", Coordinates: coords}
+}
+
+func (c GeoCollection) geoJSON() geoJSON {
+	items := make([]geoJSON, 0, len(c))
+	for _, g := range c {
+		items = append(items, g.geoJSON())
+	}
+	return geoJSON{Type: "GeometryCollection", Geometries: items}
+}
+
+func lineCoords(points []GeoPoint) [][2]float64 {
+	out := make([][2]float64, 0, len(points))
+	for _, p := range points {
+		out = append(out, p.coords())
+	}
+	return out
+}
+
+func polygonCoords(lines []GeoLine) [][][2]float64 {
+	out := make([][][2]float64, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, lineCoords(l))
+	}
+	return out
+}
+
+func (p GeoPoint) MarshalJSON() ([]byte, error)        { return json.Marshal(p.geoJSON()) }
+func (l GeoLine) MarshalJSON() ([]byte, error)         { return json.Marshal(l.geoJSON()) }
+func (p GeoPolygon) MarshalJSON() ([]byte, error)      { return json.Marshal(p.geoJSON()) }
+func (m GeoMultiPoint) MarshalJSON() ([]byte, error)   { return json.Marshal(m.geoJSON()) }
+func (m GeoMultiLine) MarshalJSON() ([]byte, error)    { return json.Marshal(m.geoJSON()) }
+func (m GeoMultiPolygon) MarshalJSON() ([]byte, error) { return json.Marshal(m.geoJSON()) }
+func (c GeoCollection) MarshalJSON() ([]byte, error)   { return json.Marshal(c.geoJSON()) }
+
+// DecodeGeometryJSON decodes a GeoJSON geometry object.
+func DecodeGeometryJSON(data []byte) (Geometry, error) {
+	var raw struct {
+		Type        string            `json:"type"`
+		Coordinates json.RawMessage   `json:"coordinates"`
+		Geometries  []json.RawMessage `json:"geometries"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("geometry: %w", err)
+	}
+	switch raw.Type {
+	case "Point":
+		var c [2]float64
+		if err := json.Unmarshal(raw.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("geometry point: %w", err)
+		}
+		return GeoPoint{Longitude: c[0], Latitude: c[1]}, nil
+	case "LineString", "MultiPoint":
+		var c [][2]float64
+		if err := json.Unmarshal(raw.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("geometry %s: %w", raw.Type, err)
+		}
+		if raw.Type == "MultiPoint" {
+			return GeoMultiPoint(pointsFromCoords(c)), nil
+		}
+		return GeoLine(pointsFromCoords(c)), nil
+	case "Polygon", "MultiLineString":
+		var c [][][2]float64
+		if err := json.Unmarshal(raw.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("geometry %s: %w", raw.Type, err)
+		}
+		if raw.Type == "MultiLineString" {
+			return GeoMultiLine(linesFromCoords(c)), nil
+		}
+		return GeoPolygon(linesFromCoords(c)), nil
+	case "MultiPolygon":
+		var c [][][][2]float64
+		if err := json.Unmarshal(raw.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("geometry %s: %w", raw.Type, err)
+		}
+		out := make(GeoMultiPolygon, 0, len(c))
+		for _, p := range c {
+			out = append(out, GeoPolygon(linesFromCoords(p)))
+		}
+		return out, nil
+	case "GeometryCollection":
+		out := make(GeoCollection, 0, len(raw.Geometries))
+		for _, item := range raw.Geometries {
+			g, err := DecodeGeometryJSON(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, g)
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("geometry: unknown type %q", raw.Type)
+}
+
+func pointsFromCoords(coords [][2]float64) []GeoPoint {
+	out := make([]GeoPoint, 0, len(coords))
+	for _, c := range coords {
+		out = append(out, GeoPoint{Longitude: c[0], Latitude: c[1]})
+	}
+	return out
+}
+
+func linesFromCoords(coords [][][2]float64) []GeoLine {
+	out := make([]GeoLine, 0, len(coords))
+	for _, c := range coords {
+		out = append(out, GeoLine(pointsFromCoords(c)))
+	}
+	return out
+}
+
+func (p *GeoPoint) UnmarshalJSON(data []byte) error        { return unmarshalGeometryJSON(data, p) }
+func (l *GeoLine) UnmarshalJSON(data []byte) error         { return unmarshalGeometryJSON(data, l) }
+func (p *GeoPolygon) UnmarshalJSON(data []byte) error      { return unmarshalGeometryJSON(data, p) }
+func (m *GeoMultiPoint) UnmarshalJSON(data []byte) error   { return unmarshalGeometryJSON(data, m) }
+func (m *GeoMultiLine) UnmarshalJSON(data []byte) error    { return unmarshalGeometryJSON(data, m) }
+func (m *GeoMultiPolygon) UnmarshalJSON(data []byte) error { return unmarshalGeometryJSON(data, m) }
+func (c *GeoCollection) UnmarshalJSON(data []byte) error   { return unmarshalGeometryJSON(data, c) }
+
+func unmarshalGeometryJSON[T Geometry](data []byte, out *T) error {
+	g, err := DecodeGeometryJSON(data)
+	if err != nil {
+		return err
+	}
+	v, ok := g.(T)
+	if !ok {
+		return fmt.Errorf("geometry: expected %s, got %s", (*out).GeometryKind(), g.GeometryKind())
+	}
+	*out = v
+	return nil
+}
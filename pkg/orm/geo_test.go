@@ -0,0 +1,87 @@
+package orm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+func TestGeoCBORRoundTrip(t *testing.T) {
+	square := GeoPolygon{GeoLine{
+		NewGeoPoint(0, 0), NewGeoPoint(1, 0), NewGeoPoint(1, 1), NewGeoPoint(0, 0),
+	}}
+	values := []Geometry{
+		NewGeoPoint(-0.118092, 51.509865),
+		GeoLine{NewGeoPoint(0, 0), NewGeoPoint(1, 1)},
+		square,
+		GeoMultiPoint{NewGeoPoint(1, 2)},
+		GeoMultiLine{GeoLine{NewGeoPoint(0, 0), NewGeoPoint(2, 2)}},
+		GeoMultiPolygon{square},
+		GeoCollection{NewGeoPoint(3, 4), square},
+	}
+	for _, v := range values {
+		data, err := cbor.Marshal(v)
+		if err != nil {
+			t.Fatalf("%s: marshal: %v", v.GeometryKind(), err)
+		}
+		got, err := DecodeGeometryCBOR(data)
+		if err != nil {
+			t.Fatalf("%s: decode: %v", v.GeometryKind(), err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Fatalf("%s: round trip mismatch: %#v", v.GeometryKind(), got)
+		}
+	}
+}
+
+func TestGeoPointCBORTag(t *testing.T) {
+	data, err := cbor.Marshal(NewGeoPoint(1.5, 2.5))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var raw cbor.RawTag
+	if err := cbor.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("unmarshal tag: %v", err)
+	}
+	if raw.Number != tagGeometryPoint {
+		t.Fatalf("unexpected tag %d", raw.Number)
+	}
+
+	var line GeoLine
+	if err := cbor.Unmarshal(data, &line); err == nil {
+		t.Fatalf("expected tag mismatch error")
+	}
+}
+
+func TestGeoJSON(t *testing.T) {
+	type place struct {
+		Location GeoPoint   `json:"location"`
+		Area     GeoPolygon `json:"area"`
+	}
+	in := place{
+		Location: NewGeoPoint(10, 20),
+		Area:     GeoPolygon{GeoLine{NewGeoPoint(0, 0), NewGeoPoint(1, 0), NewGeoPoint(0, 0)}},
+	}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want := `{"location":{"type":"Point","coordinates":[10,20]},"area":{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,0]]]}}`
+	if string(data) != want {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var out place
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("unexpected round trip: %#v", out)
+	}
+
+	var p GeoPoint
+	if err := json.Unmarshal([]byte(`{"type":"LineString","coordinates":[[0,0]]}`), &p); err == nil {
+		t.Fatalf("expected kind mismatch error")
+	}
+}
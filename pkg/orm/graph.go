@@ -0,0 +1,80 @@
+package orm
+
+import (
+	"context"
+	"sort"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// Ref is a typed reference to a record of T. ID[T, K], SimpleID[T] and
+// LinkOne[T] implement it.
+type Ref[T any] interface {
+	RecordID() models.RecordID
+	refersTo(T)
+}
+
+func (ID[T, K]) refersTo(T)    {}
+func (SimpleID[T]) refersTo(T) {}
+func (LinkOne[T]) refersTo(T)  {}
+
+// RecordID returns the linked record ID, so links can be used as a Ref.
+func (l LinkOne[T]) RecordID() models.RecordID {
+	return l.ID()
+}
+
+// Query is a SELECT whose rows decode as T.
+type Query[T any] struct {
+	*qb.SelectBuilder
+}
+
+// All runs the query and decodes every row, running AfterLoad hooks.
+func (q Query[T]) All(ctx context.Context, exec *Executor) ([]T, error) {
+	rows, err := exec.Exec(ctx, q.SelectBuilder)
+	if err != nil {
+		return nil, err
+	}
+	return loadRows[T](ctx, rows)
+}
+
+// RelateEdge builds RELATE from->edge->to, setting fields on the new edge.
+// The edge's id, in and out are managed by RELATE and skipped.
+func RelateEdge(from models.RecordID, edge qb.Table, to models.RecordID, fields map[string]any) *qb.RelateBuilder {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		switch k {
+		case "id", "in", "out":
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	stmt := qb.Relate(qb.V(from), edge, qb.V(to))
+	if len(keys) > 0 {
+		assigns := make([]qb.Assignment, 0, len(keys))
+		for _, k := range keys {
+			assigns = append(assigns, qb.Set(qb.I(k), fields[k]))
+		}
+		stmt.Set(assigns...)
+	}
+	return stmt
+}
+
+// Outgoing selects the T records reached from `from` through edge E:
+// SELECT * FROM $from->edge->t, with from bound as a parameter.
+func Outgoing[E Edge, T Model](from models.RecordID) Query[T] {
+	var edge E
+	var target T
+	path := qb.GraphFrom(from, "->"+edge.Table().Name()+"->"+target.Table().Name())
+	return Query[T]{qb.Select().From(path)}
+}
+
+// Incoming selects the T records linking to `to` through edge E:
+// SELECT * FROM $to<-edge<-t, with to bound as a parameter.
+func Incoming[E Edge, T Model](to models.RecordID) Query[T] {
+	var edge E
+	var source T
+	path := qb.GraphFrom(to, "<-"+edge.Table().Name()+"<-"+source.Table().Name())
+	return Query[T]{qb.Select().From(path)}
+}
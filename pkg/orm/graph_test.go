@@ -0,0 +1,37 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+func TestQueryAllDecodesRows(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"name": "Ann"}}}
+	users, err := Incoming[likes, linkUser](models.NewRecordID("post", 1)).All(context.Background(), NewExecutor(db))
+	if err != nil {
+		t.Fatalf("all: %v", err)
+	}
+	if db.last() != "SELECT * FROM $p1<-likes<-user" {
+		t.Fatalf("unexpected query: %s", db.last())
+	}
+	if vars := db.vars[len(db.vars)-1]; vars["p1"] != models.NewRecordID("post", 1) {
+		t.Fatalf("expected the record bound as a parameter, got %v", vars)
+	}
+	if len(users) != 1 || users[0].Name != "Ann" {
+		t.Fatalf("unexpected users: %+v", users)
+	}
+}
+
+func TestRelateEdgeSkipsManagedFields(t *testing.T) {
+	stmt := RelateEdge(models.NewRecordID("user", "a-b"), likes{}.Table(), models.NewRecordID("post", 1),
+		map[string]any{"id": "x", "in": "y", "out": "z", "weight": 2, "at": "now"})
+	q := stmt.Build()
+	if q.Text != "RELATE $p1 -> likes -> $p2 SET at = $p3, weight = $p4" {
+		t.Fatalf("unexpected relate: %s", q.Text)
+	}
+	if q.Args["p1"] != models.NewRecordID("user", "a-b") || q.Args["p2"] != models.NewRecordID("post", 1) {
+		t.Fatalf("expected records bound as parameters, got %v", q.Args)
+	}
+}
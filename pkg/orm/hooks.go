@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"context"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// Lifecycle hooks are optional interfaces on model pointers, invoked by
+// Repository. An error from a Before hook aborts the operation before any
+// statement runs; an error from an After hook is returned to the caller
+// after the statement has run.
+
+// BeforeCreateHook runs before Create encodes the model.
+type BeforeCreateHook interface {
+	BeforeCreate(ctx context.Context) error
+}
+
+// AfterCreateHook runs on the stored record returned by Create.
+type AfterCreateHook interface {
+	AfterCreate(ctx context.Context) error
+}
+
+// BeforeUpdateHook runs before Update encodes the model.
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook runs on the stored record returned by Update.
+type AfterUpdateHook interface {
+	AfterUpdate(ctx context.Context) error
+}
+
+// BeforeDeleteHook runs before Delete, on the record as loaded by id. Delete
+// returns ErrNotFound without running it if there is no such record.
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context, id qb.Node) error
+}
+
+// AfterDeleteHook runs after Delete, on the record as it was before the
+// delete. It does not run if nothing was deleted.
+type AfterDeleteHook interface {
+	AfterDelete(ctx context.Context, id qb.Node) error
+}
+
+// AfterLoadHook runs on every record decoded as a model: by a repository,
+// Query.All, Iterate, DecodeResult and DecodeOp.
+type AfterLoadHook interface {
+	AfterLoad(ctx context.Context) error
+}
+
+func callHook[H any](v any, call func(H) error) error {
+	if h, ok := v.(H); ok {
+		return call(h)
+	}
+	return nil
+}
+
+func afterLoad[T any](ctx context.Context, v *T) error {
+	return callHook(v, func(h AfterLoadHook) error { return h.AfterLoad(ctx) })
+}
+
+// loadRow decodes row as T and runs its AfterLoad hook.
+func loadRow[T any](ctx context.Context, row map[string]any) (T, error) {
+	v, err := DecodeRow[T](row)
+	if err == nil {
+		err = afterLoad(ctx, &v)
+	}
+	return v, err
+}
+
+// loadRows decodes rows as T and runs their AfterLoad hooks.
+func loadRows[T any](ctx context.Context, rows []map[string]any) ([]T, error) {
+	out, err := DecodeRows[T](rows)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if err := afterLoad(ctx, &out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
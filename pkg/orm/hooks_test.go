@@ -0,0 +1,172 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+var hookEvents []string
+
+type hookedNote struct {
+	ID     string `json:"id,omitempty"`
+	Title  string `json:"title"`
+	Loaded bool   `json:"-"`
+}
+
+func (hookedNote) Table() qb.Table { return qb.T("note") }
+
+func (n *hookedNote) BeforeCreate(ctx context.Context) error {
+	if n.Title == "abort" {
+		return errors.New("rejected")
+	}
+	n.Title = strings.ToLower(n.Title)
+	hookEvents = append(hookEvents, "before_create")
+	return nil
+}
+
+func (n *hookedNote) AfterCreate(ctx context.Context) error {
+	hookEvents = append(hookEvents, "after_create:"+n.ID)
+	return nil
+}
+
+func (n *hookedNote) BeforeUpdate(ctx context.Context) error {
+	hookEvents = append(hookEvents, "before_update")
+	return nil
+}
+
+func (n *hookedNote) AfterUpdate(ctx context.Context) error {
+	hookEvents = append(hookEvents, "after_update")
+	return nil
+}
+
+func (n *hookedNote) BeforeDelete(ctx context.Context, id qb.Node) error {
+	hookEvents = append(hookEvents, "before_delete:"+n.ID)
+	return nil
+}
+
+func (n *hookedNote) AfterDelete(ctx context.Context, id qb.Node) error {
+	hookEvents = append(hookEvents, "after_delete:"+n.ID)
+	return nil
+}
+
+func (n *hookedNote) AfterLoad(ctx context.Context) error {
+	n.Loaded = true
+	hookEvents = append(hookEvents, "after_load")
+	return nil
+}
+
+type archivedHookedNote struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+}
+
+func (archivedHookedNote) Table() qb.Table         { return qb.T("note") }
+func (archivedHookedNote) SoftDeleteField() string { return "deleted_at" }
+
+func (n *archivedHookedNote) AfterDelete(ctx context.Context, id qb.Node) error {
+	hookEvents = append(hookEvents, "after_delete:"+n.ID)
+	return nil
+}
+
+func TestRepositorySoftDeleteHooksSkipDeleted(t *testing.T) {
+	hookEvents = nil
+	db := &recordingDB{}
+	repo := NewRepository[archivedHookedNote](NewExecutor(db))
+	if err := repo.Delete(context.Background(), qb.I("note:1")); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got, want := db.last(), "UPDATE note:1 SET deleted_at = time::now() WHERE deleted_at IS NONE RETURN BEFORE"; got != want {
+		t.Fatalf("unexpected soft delete:\n%s\nexpected:\n%s", got, want)
+	}
+	if len(hookEvents) != 0 {
+		t.Fatalf("expected no hook for an already deleted record, got %v", hookEvents)
+	}
+}
+
+func TestRepositoryHooks(t *testing.T) {
+	hookEvents = nil
+	db := &recordingDB{rows: []map[string]any{{"id": "note:1", "title": "hello"}}}
+	repo := NewRepository[hookedNote](NewExecutor(db))
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, hookedNote{Title: "HELLO"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if payload := db.vars[len(db.vars)-1]["p1"].(map[string]any); payload["title"] != "hello" {
+		t.Fatalf("expected BeforeCreate to normalise payload, got %v", payload)
+	}
+	if !created.Loaded {
+		t.Fatalf("expected AfterLoad on created record")
+	}
+	if _, err := repo.Update(ctx, qb.I("note:1"), hookedNote{Title: "x"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(ctx, qb.I("note:1")); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := db.sql[len(db.sql)-2:]; got[0] != "SELECT * FROM note:1" || got[1] != "DELETE note:1 RETURN BEFORE" {
+		t.Fatalf("unexpected delete statements: %v", got)
+	}
+	found, err := repo.Find(ctx, qb.Condition{})
+	if err != nil || len(found) != 1 || !found[0].Loaded {
+		t.Fatalf("expected loaded rows: %+v %v", found, err)
+	}
+
+	want := []string{
+		"before_create", "after_load", "after_create:note:1",
+		"before_update", "after_load", "after_update",
+		"after_load", "before_delete:note:1", "after_load", "after_delete:note:1",
+		"after_load",
+	}
+	if !reflect.DeepEqual(hookEvents, want) {
+		t.Fatalf("unexpected hook order:\n%v\nwant:\n%v", hookEvents, want)
+	}
+}
+
+func TestRepositoryBeforeHookAborts(t *testing.T) {
+	db := &recordingDB{}
+	repo := NewRepository[hookedNote](NewExecutor(db))
+	if _, err := repo.Create(context.Background(), hookedNote{Title: "abort"}); err == nil || err.Error() != "rejected" {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if len(db.sql) != 0 {
+		t.Fatalf("expected no statement to run, got %v", db.sql)
+	}
+}
+
+func TestRepositoryDeleteHooksNeedRecord(t *testing.T) {
+	db := &recordingDB{}
+	repo := NewRepository[hookedNote](NewExecutor(db))
+	if err := repo.Delete(context.Background(), qb.I("note:9")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(db.sql) != 1 || !strings.HasPrefix(db.sql[0], "SELECT") {
+		t.Fatalf("expected only the load to run, got %v", db.sql)
+	}
+}
+
+func TestAfterLoadOnQueriesAndOps(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"id": "note:1", "title": "a"}}}
+	exec := NewExecutor(db)
+	ctx := context.Background()
+
+	notes, err := Query[hookedNote]{qb.Select().From(qb.T("note"))}.All(ctx, exec)
+	if err != nil || len(notes) != 1 || !notes[0].Loaded {
+		t.Fatalf("expected Query.All to run AfterLoad: %+v %v", notes, err)
+	}
+	for n, err := range Iterate[hookedNote](ctx, exec, qb.Select().From(qb.T("note")), 10) {
+		if err != nil || !n.Loaded {
+			t.Fatalf("expected Iterate to run AfterLoad: %+v %v", n, err)
+		}
+	}
+	op := &Op{Rows: []map[string]any{{"id": "note:2", "title": "b"}}}
+	if notes, err := DecodeOp[hookedNote](ctx, op); err != nil || len(notes) != 1 || !notes[0].Loaded {
+		t.Fatalf("expected DecodeOp to run AfterLoad: %+v %v", notes, err)
+	}
+}
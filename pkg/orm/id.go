@@ -1,6 +1,23 @@
 package orm
 
-// ID represents a typed record identifier.
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// ErrInvalidID is returned when a record ID cannot be parsed or belongs to a
+// different table.
+var ErrInvalidID = errors.New("orm: invalid record id")
+
+// ID represents a typed record identifier. The table comes from T's Table()
+// method; K is the key, which may be a scalar or, for composite keys, an
+// array or struct.
 type ID[T any, K comparable] struct {
 	Value K
 }
@@ -9,6 +26,110 @@ func NewID[T any, K comparable](value K) ID[T, K] {
 	return ID[T, K]{Value: value}
 }
 
+// ParseID parses "table:key". The table must match T's table; the key is
+// unescaped (⟨...⟩ or `...`) for string keys and decoded as JSON otherwise.
+func ParseID[T any, K comparable](s string) (ID[T, K], error) {
+	var id ID[T, K]
+	err := id.UnmarshalText([]byte(s))
+	return id, err
+}
+
+// Table returns the record table.
+func (id ID[T, K]) Table() qb.Table {
+	return qb.T(tableOf[T]())
+}
+
+// RecordTable returns the record table name, for orm.TargetTable.
+func (id ID[T, K]) RecordTable() string {
+	return tableOf[T]()
+}
+
+// IsZero reports whether the key is unset.
+func (id ID[T, K]) IsZero() bool {
+	var zero K
+	return id.Value == zero
+}
+
+// RecordID converts the identifier to the driver's record ID type.
+func (id ID[T, K]) RecordID() models.RecordID {
+	return models.NewRecordID(tableOf[T](), id.Value)
+}
+
+// String renders the identifier as a SurrealQL record literal.
+func (id ID[T, K]) String() string {
+	return recordLiteral(tableOf[T](), id.Value)
+}
+
+// Node binds the identifier as a record ID parameter, e.g. as a statement
+// target: qb.Update(id.Node()).
+func (id ID[T, K]) Node() qb.Node {
+	return qb.V(id.RecordID()).Node()
+}
+
+func (id ID[T, K]) MarshalCBOR() ([]byte, error) {
+	if id.IsZero() {
+		return cbor.Marshal(nil)
+	}
+	return marshalRecordID(tableOf[T](), id.Value)
+}
+
+func (id *ID[T, K]) UnmarshalCBOR(data []byte) error {
+	table, key, err := unmarshalRecordID[K](data)
+	if err != nil {
+		return err
+	}
+	if err := checkTable[T](table); err != nil {
+		return err
+	}
+	id.Value = key
+	return nil
+}
+
+func (id ID[T, K]) MarshalText() ([]byte, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	return []byte(id.String()), nil
+}
+
+func (id *ID[T, K]) UnmarshalText(text []byte) error {
+	table, key, err := parseRecordLiteral[K](string(text))
+	if err != nil {
+		return err
+	}
+	if err := checkTable[T](table); err != nil {
+		return err
+	}
+	id.Value = key
+	return nil
+}
+
+func (id ID[T, K]) MarshalJSON() ([]byte, error) {
+	if id.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(id.String())
+}
+
+func (id *ID[T, K]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*id = ID[T, K]{}
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidID, data)
+	}
+	return id.UnmarshalText([]byte(s))
+}
+
+func (id *ID[T, K]) setRecordID(table string, key any) error {
+	if err := checkTable[T](table); err != nil {
+		return err
+	}
+	return decodeValue(key, &id.Value)
+}
+
 // SimpleID is a string-based identifier.
 type SimpleID[T any] struct {
 	Value string
@@ -17,3 +138,193 @@ type SimpleID[T any] struct {
 func NewSimpleID[T any](value string) SimpleID[T] {
 	return SimpleID[T]{Value: value}
 }
+
+// ParseSimpleID parses "table:key" into a SimpleID.
+func ParseSimpleID[T any](s string) (SimpleID[T], error) {
+	var id SimpleID[T]
+	err := id.UnmarshalText([]byte(s))
+	return id, err
+}
+
+func (id SimpleID[T]) asID() ID[T, string] {
+	return ID[T, string]{Value: id.Value}
+}
+
+func (id SimpleID[T]) Table() qb.Table              { return id.asID().Table() }
+func (id SimpleID[T]) RecordTable() string          { return id.asID().RecordTable() }
+func (id SimpleID[T]) IsZero() bool                 { return id.Value == "" }
+func (id SimpleID[T]) RecordID() models.RecordID    { return id.asID().RecordID() }
+func (id SimpleID[T]) String() string               { return id.asID().String() }
+func (id SimpleID[T]) Node() qb.Node                { return id.asID().Node() }
+func (id SimpleID[T]) MarshalCBOR() ([]byte, error) { return id.asID().MarshalCBOR() }
+func (id SimpleID[T]) MarshalText() ([]byte, error) { return id.asID().MarshalText() }
+func (id SimpleID[T]) MarshalJSON() ([]byte, error) { return id.asID().MarshalJSON() }
+func (id *SimpleID[T]) UnmarshalCBOR(data []byte) error {
+	return id.update(func(inner *ID[T, string]) error { return inner.UnmarshalCBOR(data) })
+}
+
+func (id *SimpleID[T]) UnmarshalText(text []byte) error {
+	return id.update(func(inner *ID[T, string]) error { return inner.UnmarshalText(text) })
+}
+
+func (id *SimpleID[T]) UnmarshalJSON(data []byte) error {
+	return id.update(func(inner *ID[T, string]) error { return inner.UnmarshalJSON(data) })
+}
+
+func (id *SimpleID[T]) setRecordID(table string, key any) error {
+	return id.update(func(inner *ID[T, string]) error { return inner.setRecordID(table, key) })
+}
+
+func (id *SimpleID[T]) update(fn func(*ID[T, string]) error) error {
+	inner := id.asID()
+	if err := fn(&inner); err != nil {
+		return err
+	}
+	id.Value = inner.Value
+	return nil
+}
+
+// recordIDSetter is implemented by ID and SimpleID so row decoding can
+// assign driver record IDs without a CBOR round trip.
+type recordIDSetter interface {
+	setRecordID(table string, key any) error
+}
+
+// tableOf returns the table of model type T, or "" if T has no Table().
+func tableOf[T any]() string {
+	var zero T
+	if m, ok := any(zero).(Model); ok {
+		return m.Table().Name()
+	}
+	if m, ok := any(&zero).(Model); ok {
+		return m.Table().Name()
+	}
+	return ""
+}
+
+func checkTable[T any](table string) error {
+	if want := tableOf[T](); want != "" && table != want {
+		return fmt.Errorf("%w: table %q, want %q", ErrInvalidID, table, want)
+	}
+	return nil
+}
+
+// recordContent is the payload of the CBOR record ID tag: [table, key].
+type recordContent[K any] struct {
+	_     struct{} `cbor:",toarray"`
+	Table string
+	Key   K
+}
+
+func marshalRecordID[K any](table string, key K) ([]byte, error) {
+	if table == "" {
+		return nil, fmt.Errorf("%w: missing table", ErrInvalidID)
+	}
+	return cbor.Marshal(cbor.Tag{
+		Number:  models.TagRecordID,
+		Content: recordContent[K]{Table: table, Key: key},
+	})
+}
+
+func unmarshalRecordID[K any](data []byte) (string, K, error) {
+	var key K
+	var tag cbor.RawTag
+	if err := cbor.Unmarshal(data, &tag); err != nil {
+		return "", key, err
+	}
+	if tag.Number != models.TagRecordID {
+		return "", key, fmt.Errorf("%w: unexpected CBOR tag %d", ErrInvalidID, tag.Number)
+	}
+	var content recordContent[K]
+	if err := cbor.Unmarshal(tag.Content, &content); err != nil {
+		return "", key, err
+	}
+	return content.Table, content.Key, nil
+}
+
+// recordLiteral renders table:key, escaping string keys that are not plain
+// identifiers and encoding composite keys as array/object literals. Inside
+// ⟨...⟩ a backslash escapes the next character.
+func recordLiteral(table string, key any) string {
+	switch k := key.(type) {
+	case string:
+		if plainKey(k) {
+			return table + ":" + k
+		}
+		return table + ":⟨" + angleEscaper.Replace(k) + "⟩"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%s:%d", table, k)
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return fmt.Sprintf("%s:%v", table, key)
+	}
+	return table + ":" + string(data)
+}
+
+// plainKey reports whether s can appear unescaped after "table:".
+func plainKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	digits := true
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+		case r == '_':
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			digits = false
+		default:
+			return false
+		}
+	}
+	return !digits
+}
+
+func parseRecordLiteral[K any](s string) (string, K, error) {
+	var key K
+	idx := strings.Index(s, ":")
+	if idx <= 0 || idx == len(s)-1 {
+		return "", key, fmt.Errorf("%w: %q", ErrInvalidID, s)
+	}
+	table, raw := s[:idx], s[idx+1:]
+	if p, ok := any(&key).(*string); ok {
+		*p = unescapeKey(raw)
+		return table, key, nil
+	}
+	if err := json.Unmarshal([]byte(unescapeKey(raw)), &key); err != nil {
+		return "", key, fmt.Errorf("%w: %q: %v", ErrInvalidID, s, err)
+	}
+	return table, key, nil
+}
+
+var angleEscaper = strings.NewReplacer(`\`, `\\`, "⟩", `\⟩`)
+
+func unescapeKey(raw string) string {
+	switch {
+	case strings.HasPrefix(raw, "⟨") && strings.HasSuffix(raw, "⟩"):
+		return unescapeBackslashes(strings.TrimSuffix(strings.TrimPrefix(raw, "⟨"), "⟩"))
+	case len(raw) >= 2 && raw[0] == '`' && raw[len(raw)-1] == '`':
+		return unescapeBackslashes(raw[1 : len(raw)-1])
+	}
+	return raw
+}
+
+// unescapeBackslashes drops each escaping backslash, keeping the character
+// it escapes.
+func unescapeBackslashes(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range s {
+		if r == '\\' && !escaped {
+			escaped = true
+			continue
+		}
+		escaped = false
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package orm
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type idUser struct{}
+
+func (idUser) Table() qb.Table { return qb.T("user") }
+
+type compositeKey struct {
+	Org  string `json:"org"`
+	Seat int    `json:"seat"`
+}
+
+func TestIDLiteral(t *testing.T) {
+	cases := []struct {
+		got  string
+		want string
+	}{
+		{NewSimpleID[idUser]("ann").String(), "user:ann"},
+		{NewSimpleID[idUser]("a-b").String(), "user:⟨a-b⟩"},
+		{NewSimpleID[idUser]("123").String(), "user:⟨123⟩"},
+		{NewID[idUser](42).String(), "user:42"},
+		{NewID[idUser]([2]string{"a", "b"}).String(), `user:["a","b"]`},
+		{NewID[idUser](compositeKey{Org: "acme", Seat: 2}).String(), `user:{"org":"acme","seat":2}`},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Fatalf("got %s, want %s", tc.got, tc.want)
+		}
+	}
+}
+
+func TestParseID(t *testing.T) {
+	id, err := ParseID[idUser, int]("user:42")
+	if err != nil || id.Value != 42 {
+		t.Fatalf("unexpected parse: %+v %v", id, err)
+	}
+	simple, err := ParseSimpleID[idUser]("user:⟨a-b⟩")
+	if err != nil || simple.Value != "a-b" {
+		t.Fatalf("unexpected parse: %+v %v", simple, err)
+	}
+	composite, err := ParseID[idUser, compositeKey](`user:{"org":"acme","seat":2}`)
+	if err != nil || composite.Value != (compositeKey{Org: "acme", Seat: 2}) {
+		t.Fatalf("unexpected parse: %+v %v", composite, err)
+	}
+	if _, err := ParseSimpleID[idUser]("post:1"); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expected table mismatch, got %v", err)
+	}
+	if _, err := ParseSimpleID[idUser]("nocolon"); !errors.Is(err, ErrInvalidID) {
+		t.Fatalf("expected invalid id, got %v", err)
+	}
+}
+
+func TestIDLiteralEscapesKeys(t *testing.T) {
+	keys := []string{`a⟩; DELETE user; --`, `a\`, `a\⟩; DELETE user`, `\\⟩`}
+	for _, key := range keys {
+		lit := NewSimpleID[idUser](key).String()
+		body := strings.TrimSuffix(strings.TrimPrefix(lit, "user:⟨"), "⟩")
+		if strings.Contains(strings.ReplaceAll(strings.ReplaceAll(body, `\\`, ""), `\⟩`, ""), "⟩") {
+			t.Fatalf("key %q escapes its literal: %s", key, lit)
+		}
+		parsed, err := ParseSimpleID[idUser](lit)
+		if err != nil || parsed.Value != key {
+			t.Fatalf("round trip of %q: got %q, %v", key, parsed.Value, err)
+		}
+	}
+}
+
+func TestIDCBORMatchesDriver(t *testing.T) {
+	id := NewID[idUser]([2]string{"a", "b"})
+	data, err := surrealcbor.Marshal(id)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var rid models.RecordID
+	if err := surrealcbor.Unmarshal(data, &rid); err != nil {
+		t.Fatalf("driver decode: %v", err)
+	}
+	if rid.Table != "user" {
+		t.Fatalf("unexpected record id: %+v", rid)
+	}
+
+	var back ID[idUser, [2]string]
+	if err := surrealcbor.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if back != id {
+		t.Fatalf("unexpected round trip: %+v", back)
+	}
+}
+
+func TestIDJSON(t *testing.T) {
+	data, err := json.Marshal(map[string]any{"id": NewSimpleID[idUser]("ann")})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"id":"user:ann"}` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+	var out struct {
+		ID SimpleID[idUser] `json:"id"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil || out.ID.Value != "ann" {
+		t.Fatalf("unexpected decode: %+v %v", out, err)
+	}
+}
+
+func TestIDFromRow(t *testing.T) {
+	var out struct {
+		id   ID[idUser, int]
+		name SimpleID[idUser]
+	}
+	row := map[string]any{"id": models.NewRecordID("user", uint64(7)), "name": "user:ann"}
+	if err := DecodeField(row, "id", &out.id); err != nil {
+		t.Fatalf("decode id: %v", err)
+	}
+	if err := DecodeField(row, "name", &out.name); err != nil {
+		t.Fatalf("decode name: %v", err)
+	}
+	if out.id.Value != 7 || out.name.Value != "ann" {
+		t.Fatalf("unexpected decode: %+v", out)
+	}
+}
+
+func TestIDAsStatementTarget(t *testing.T) {
+	id := NewSimpleID[idUser]("ann")
+	q := qb.Build(qb.Update(id.Node()).Set(qb.Set(qb.I("name"), "Ann")))
+	if q.Text != "UPDATE $p1 SET name = $p2" {
+		t.Fatalf("unexpected query: %s", q.Text)
+	}
+	if q.Args["p1"] != id.RecordID() {
+		t.Fatalf("expected the id bound as a record id, got %#v", q.Args["p1"])
+	}
+	if TargetTable(id.Node()) != "user" || TargetTable(qb.V(id)) != "user" {
+		t.Fatalf("unexpected target table")
+	}
+}
@@ -0,0 +1,131 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// QueryInfo describes one query round trip. Statement is nil for queries
+// that did not come from a qb statement, e.g. migrations run through
+// Intercept. Duration, Rows and Err are set before AfterQuery runs.
+type QueryInfo struct {
+	Statement qb.Statement
+	Text      string
+	Args      map[string]any
+	Duration  time.Duration
+	Rows      int
+	Err       error
+}
+
+// Interceptor observes query execution. BeforeQuery may return a derived
+// context, e.g. carrying a tracing span, which is passed to the DB and to
+// AfterQuery. Interceptors run BeforeQuery in order and AfterQuery in
+// reverse order.
+type Interceptor interface {
+	BeforeQuery(ctx context.Context, info *QueryInfo) context.Context
+	AfterQuery(ctx context.Context, info *QueryInfo)
+}
+
+// InterceptorFuncs adapts plain functions to Interceptor; nil fields are
+// skipped.
+type InterceptorFuncs struct {
+	Before func(ctx context.Context, info *QueryInfo) context.Context
+	After  func(ctx context.Context, info *QueryInfo)
+}
+
+func (f InterceptorFuncs) BeforeQuery(ctx context.Context, info *QueryInfo) context.Context {
+	if f.Before == nil {
+		return ctx
+	}
+	return f.Before(ctx, info)
+}
+
+func (f InterceptorFuncs) AfterQuery(ctx context.Context, info *QueryInfo) {
+	if f.After != nil {
+		f.After(ctx, info)
+	}
+}
+
+// WithInterceptor appends a query interceptor.
+func WithInterceptor(i Interceptor) ExecutorOption {
+	return func(e *Executor) {
+		e.Interceptors = append(e.Interceptors, i)
+	}
+}
+
+// Intercept wraps db so every query passes through the interceptors. Use it
+// for components that talk to a DB directly, such as the migrator.
+func Intercept(db DB, interceptors ...Interceptor) DB {
+	return &interceptedDB{db: db, interceptors: interceptors}
+}
+
+type interceptedDB struct {
+	db           DB
+	interceptors []Interceptor
+}
+
+func (d *interceptedDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	var rows []map[string]any
+	err := intercept(ctx, d.interceptors, &QueryInfo{Text: sql, Args: vars}, func(ctx context.Context) (int, error) {
+		var err error
+		rows, err = d.db.Query(ctx, sql, vars)
+		return len(rows), err
+	})
+	return rows, err
+}
+
+func (d *interceptedDB) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]StatementResult, error) {
+	db, ok := d.db.(BatchDB)
+	if !ok {
+		return nil, ErrBatchUnsupported
+	}
+	var results []StatementResult
+	err := intercept(ctx, d.interceptors, &QueryInfo{Text: sql, Args: vars}, func(ctx context.Context) (int, error) {
+		var err error
+		results, err = db.QueryBatch(ctx, sql, vars)
+		return batchOutcome(results, err)
+	})
+	if results != nil {
+		return results, nil
+	}
+	return nil, err
+}
+
+// intercept runs call between the interceptors' hooks and returns its error.
+func intercept(ctx context.Context, interceptors []Interceptor, info *QueryInfo, call func(context.Context) (int, error)) error {
+	for _, i := range interceptors {
+		ctx = i.BeforeQuery(ctx, info)
+	}
+	start := time.Now()
+	info.Rows, info.Err = call(ctx)
+	info.Duration = time.Since(start)
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptors[i].AfterQuery(ctx, info)
+	}
+	return info.Err
+}
+
+// batchOutcome totals the rows of a batch and, when the round trip itself
+// succeeded, reports the statement error that failed it: as in
+// UnitOfWork.Commit, the first one that is not ErrTransactionFailed.
+func batchOutcome(results []StatementResult, err error) (int, error) {
+	rows := 0
+	var cause error
+	for _, r := range results {
+		rows += len(r.Rows)
+		stmtErr := ClassifyError(r.Err)
+		if stmtErr == nil {
+			continue
+		}
+		if cause == nil || errors.Is(cause, ErrTransactionFailed) && !errors.Is(stmtErr, ErrTransactionFailed) {
+			cause = stmtErr
+		}
+	}
+	if err != nil {
+		return rows, err
+	}
+	return rows, cause
+}
@@ -0,0 +1,115 @@
+package orm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type ctxKey struct{}
+
+func TestExecutorInterceptors(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"id": "note:1"}, {"id": "note:2"}}}
+	var calls []string
+	var seen *QueryInfo
+	outer := InterceptorFuncs{
+		Before: func(ctx context.Context, info *QueryInfo) context.Context {
+			calls = append(calls, "outer before")
+			return context.WithValue(ctx, ctxKey{}, "span")
+		},
+		After: func(ctx context.Context, info *QueryInfo) {
+			calls = append(calls, "outer after")
+		},
+	}
+	inner := InterceptorFuncs{
+		Before: func(ctx context.Context, info *QueryInfo) context.Context {
+			calls = append(calls, "inner before")
+			return ctx
+		},
+		After: func(ctx context.Context, info *QueryInfo) {
+			if ctx.Value(ctxKey{}) != "span" {
+				t.Fatalf("expected derived context in after hook")
+			}
+			calls = append(calls, "inner after")
+			seen = info
+		},
+	}
+	exec := NewExecutor(db, WithInterceptor(outer), WithInterceptor(inner))
+	if _, err := exec.Exec(context.Background(), qb.Select().From(qb.T("note")).Where(qb.I("title").Eq("a"))); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if strings.Join(calls, ", ") != "outer before, inner before, inner after, outer after" {
+		t.Fatalf("unexpected call order: %v", calls)
+	}
+	if seen.Statement == nil || seen.Text != "SELECT * FROM note WHERE title = $p1" || seen.Args["p1"] != "a" || seen.Rows != 2 {
+		t.Fatalf("unexpected query info: %+v", seen)
+	}
+}
+
+func TestSlogInterceptorRedactsArgs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	failed := errors.New("boom")
+	db := Intercept(&recordingDB{err: failed}, NewSlogInterceptor(logger, LogOptions{Redact: RedactArgs("password")}))
+
+	_, err := db.Query(context.Background(), "SELECT * FROM user WHERE name = $name AND pass = $password", map[string]any{"name": "ann", "password": "secret"})
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected query error, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"level=ERROR", `msg="surreal query failed"`, "args.name=ann", "args.password=[REDACTED]", "error=boom"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected log to contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret") {
+		t.Fatalf("password leaked into log:\n%s", out)
+	}
+}
+
+func TestSlogInterceptorSlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	interceptor := NewSlogInterceptor(logger, LogOptions{Level: slog.LevelDebug, SlowThreshold: time.Millisecond})
+	interceptor.AfterQuery(context.Background(), &QueryInfo{Text: "SELECT 1", Args: map[string]any{"p1": 1}, Duration: time.Second})
+	out := buf.String()
+	if !strings.Contains(out, `msg="slow surreal query"`) || !strings.Contains(out, "args.p1=[REDACTED]") {
+		t.Fatalf("unexpected log:\n%s", out)
+	}
+	buf.Reset()
+	interceptor.AfterQuery(context.Background(), &QueryInfo{Text: "SELECT 1"})
+	if buf.Len() != 0 {
+		t.Fatalf("expected debug log to be filtered, got %s", buf.String())
+	}
+}
+
+type recordingMetrics struct {
+	ops  []string
+	rows int
+}
+
+func (m *recordingMetrics) ObserveQuery(op string, d time.Duration, rows int, err error) {
+	m.ops = append(m.ops, op)
+	m.rows += rows
+}
+
+func TestMetricsInterceptor(t *testing.T) {
+	metrics := &recordingMetrics{}
+	db := &batchDB{results: []StatementResult{{Rows: []map[string]any{{"id": "note:1"}}}}}
+	exec := NewExecutor(db, WithInterceptor(NewMetricsInterceptor(metrics)))
+	if _, err := exec.Exec(context.Background(), qb.Delete(qb.I("note:1"))); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if _, err := exec.ExecBatch(context.Background(), qb.BeginTransaction(), qb.CommitTransaction()); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if strings.Join(metrics.ops, ",") != "DELETE,BEGIN" || metrics.rows != 1 {
+		t.Fatalf("unexpected metrics: %+v", metrics)
+	}
+}
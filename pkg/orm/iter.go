@@ -0,0 +1,100 @@
+package orm
+
+import (
+	"context"
+	"iter"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// DefaultPageSize is the number of rows Iter and Iterate fetch per query
+// unless told otherwise.
+const DefaultPageSize = 500
+
+// WithPageSize sets the number of rows Iter fetches per query.
+func WithPageSize(n int) QueryOption {
+	return func(c *queryConfig) {
+		c.pageSize = n
+	}
+}
+
+// Iter streams the rows matching cond in id order, fetching them a page at
+// a time with keyset pagination (WHERE id > $last ORDER BY id LIMIT n) and
+// decoding each row only when it is reached, with AfterLoad hooks. The
+// first error, including ctx's once it is cancelled, is yielded and ends
+// the iteration.
+func (r *Repository[T]) Iter(ctx context.Context, cond qb.Condition, opts ...QueryOption) iter.Seq2[T, error] {
+	size := pageSizeOrDefault(newQueryConfig(opts).pageSize)
+	next := func(_ int, last map[string]any) ([]map[string]any, error) {
+		c := cond
+		if last != nil {
+			c = andCond(cond, qb.I("id").Gt(last["id"]))
+		}
+		stmt := r.Select(c, opts...).OrderBy(qb.OrderBy(qb.I("id")).Asc()).Limit(size)
+		return r.Exec.Exec(ctx, stmt)
+	}
+	return paginate(ctx, size, next, func(row map[string]any) (T, error) { return loadRow[T](ctx, row) })
+}
+
+// Iterate streams the rows of stmt as T, fetching them a page at a time
+// with START and LIMIT. stmt needs a stable ORDER BY for pages not to
+// overlap; its own START and LIMIT are replaced, and stmt itself is left
+// unchanged. A pageSize of zero means DefaultPageSize. Rows run their
+// AfterLoad hooks.
+func Iterate[T any](ctx context.Context, exec *Executor, stmt *qb.SelectBuilder, pageSize int) iter.Seq2[T, error] {
+	size := pageSizeOrDefault(pageSize)
+	next := func(seen int, _ map[string]any) ([]map[string]any, error) {
+		page := stmt.Clone().Limit(size)
+		if seen > 0 {
+			page.Start(seen)
+		}
+		return exec.Exec(ctx, page)
+	}
+	return paginate(ctx, size, next, func(row map[string]any) (T, error) { return loadRow[T](ctx, row) })
+}
+
+func pageSizeOrDefault(n int) int {
+	if n <= 0 {
+		return DefaultPageSize
+	}
+	return n
+}
+
+// paginate yields decoded rows from pages returned by next, which receives
+// the number of rows seen so far and the last row, nil for the first page.
+// A page shorter than size ends the iteration.
+func paginate[T any](ctx context.Context, size int, next func(seen int, last map[string]any) ([]map[string]any, error), decode func(map[string]any) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var (
+			zero T
+			seen int
+			last map[string]any
+		)
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+			rows, err := next(seen, last)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, row := range rows {
+				if err := ctx.Err(); err != nil {
+					yield(zero, err)
+					return
+				}
+				v, err := decode(row)
+				if !yield(v, err) || err != nil {
+					return
+				}
+			}
+			if len(rows) < size {
+				return
+			}
+			seen += len(rows)
+			last = rows[len(rows)-1]
+		}
+	}
+}
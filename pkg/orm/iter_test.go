@@ -0,0 +1,111 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// pagedDB returns one page per query.
+type pagedDB struct {
+	recordingDB
+	pages [][]map[string]any
+}
+
+func (p *pagedDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	p.sql = append(p.sql, sql)
+	p.vars = append(p.vars, vars)
+	if len(p.pages) == 0 {
+		return nil, nil
+	}
+	page := p.pages[0]
+	p.pages = p.pages[1:]
+	return page, nil
+}
+
+func notePages() [][]map[string]any {
+	return [][]map[string]any{
+		{{"id": "note:1", "title": "a"}, {"id": "note:2", "title": "b"}},
+		{{"id": "note:3", "title": "c"}},
+	}
+}
+
+func TestRepositoryIterKeysetPages(t *testing.T) {
+	db := &pagedDB{pages: notePages()}
+	repo := NewRepository[note](NewExecutor(db))
+
+	var titles []string
+	for n, err := range repo.Iter(context.Background(), qb.I("title").Neq("z"), WithPageSize(2)) {
+		if err != nil {
+			t.Fatalf("iter: %v", err)
+		}
+		titles = append(titles, n.Title)
+	}
+	if len(titles) != 3 || titles[2] != "c" {
+		t.Fatalf("unexpected titles: %v", titles)
+	}
+	if len(db.sql) != 2 {
+		t.Fatalf("expected 2 page queries, got %v", db.sql)
+	}
+	if got := db.sql[0]; got != "SELECT * FROM note WHERE title != $p1 ORDER BY id ASC LIMIT $p2" {
+		t.Fatalf("unexpected first page: %s", got)
+	}
+	if got := db.sql[1]; got != "SELECT * FROM note WHERE title != $p1 AND id > $p2 ORDER BY id ASC LIMIT $p3" {
+		t.Fatalf("unexpected second page: %s", got)
+	}
+	if db.vars[1]["p2"] != "note:2" {
+		t.Fatalf("expected keyset on last id, got %v", db.vars[1])
+	}
+}
+
+func TestRepositoryIterStopsEarly(t *testing.T) {
+	db := &pagedDB{pages: notePages()}
+	repo := NewRepository[note](NewExecutor(db))
+	for range repo.Iter(context.Background(), qb.Condition{}, WithPageSize(2)) {
+		break
+	}
+	if len(db.sql) != 1 {
+		t.Fatalf("expected a single query, got %v", db.sql)
+	}
+}
+
+func TestRepositoryIterContextCancel(t *testing.T) {
+	db := &pagedDB{pages: notePages()}
+	repo := NewRepository[note](NewExecutor(db))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []error
+	for _, err := range repo.Iter(ctx, qb.Condition{}, WithPageSize(2)) {
+		got = append(got, err)
+		cancel()
+	}
+	if len(got) != 2 || got[0] != nil || !errors.Is(got[1], context.Canceled) {
+		t.Fatalf("expected one row then context.Canceled, got %v", got)
+	}
+}
+
+func TestIterateStartLimit(t *testing.T) {
+	db := &pagedDB{pages: notePages()}
+	stmt := qb.Select().From(qb.T("note")).OrderBy(qb.OrderBy(qb.I("title")))
+	var n int
+	for _, err := range Iterate[note](context.Background(), NewExecutor(db), stmt, 2) {
+		if err != nil {
+			t.Fatalf("iterate: %v", err)
+		}
+		n++
+	}
+	if n != 3 || len(db.sql) != 2 {
+		t.Fatalf("expected 3 rows over 2 pages, got %d %v", n, db.sql)
+	}
+	if got := db.sql[1]; got != "SELECT * FROM note ORDER BY title LIMIT $p1 START $p2" {
+		t.Fatalf("unexpected second page: %s", got)
+	}
+	if db.vars[1]["p2"] != 2 {
+		t.Fatalf("expected START 2, got %v", db.vars[1])
+	}
+	if got := qb.Build(stmt).Text; got != "SELECT * FROM note ORDER BY title" {
+		t.Fatalf("expected stmt to be unchanged, got %s", got)
+	}
+}
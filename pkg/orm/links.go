@@ -1,10 +1,286 @@
 package orm
 
-// LinkSelf is a self-referential relation.
-type LinkSelf[T any] struct{}
+import (
+	"encoding/json"
+	"fmt"
 
-// LinkOne is a one-to-one relation.
-type LinkOne[T any] struct{}
+	"github.com/fxamacker/cbor/v2"
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+)
 
-// LinkMany is a one-to-many relation.
-type LinkMany[T any] struct{}
+// LinkOne is a one-to-one relation. It holds the linked record ID and, once
+// fetched (e.g. via FETCH), the linked record itself. It is written as a
+// record ID and decodes from either a record ID or a record object.
+type LinkOne[T any] struct {
+	id     models.RecordID
+	value  T
+	loaded bool
+}
+
+// NewLinkOne links to the record id without loading it.
+func NewLinkOne[T any](id models.RecordID) LinkOne[T] {
+	return LinkOne[T]{id: id}
+}
+
+// LoadedLinkOne links to an already loaded record. The ID is taken from the
+// record's "id" field.
+func LoadedLinkOne[T any](value T) LinkOne[T] {
+	return LinkOne[T]{value: value, loaded: true}
+}
+
+// IsLoaded reports whether the linked record was fetched.
+func (l LinkOne[T]) IsLoaded() bool {
+	return l.loaded
+}
+
+// IsZero reports whether the link is unset.
+func (l LinkOne[T]) IsZero() bool {
+	return !l.loaded && l.id.Table == ""
+}
+
+// ID returns the linked record ID.
+func (l LinkOne[T]) ID() models.RecordID {
+	if l.id.Table == "" && l.loaded {
+		id, _ := recordIDOfValue(l.value)
+		return id
+	}
+	return l.id
+}
+
+// Value returns the linked record, or the zero value if it was not loaded.
+func (l LinkOne[T]) Value() T {
+	return l.value
+}
+
+func (l LinkOne[T]) MarshalCBOR() ([]byte, error) {
+	id := l.ID()
+	if id.Table == "" {
+		return cbor.Marshal(nil)
+	}
+	return id.MarshalCBOR()
+}
+
+func (l *LinkOne[T]) UnmarshalCBOR(data []byte) error {
+	var raw any
+	if err := surrealcbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return l.decodeLink(raw)
+}
+
+func (l LinkOne[T]) MarshalJSON() ([]byte, error) {
+	id := l.ID()
+	if id.Table == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(recordLiteral(id.Table, id.ID))
+}
+
+func (l *LinkOne[T]) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if obj, ok := raw.(map[string]any); ok {
+		var value T
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		id, _ := recordIDOf(obj["id"])
+		*l = LinkOne[T]{id: id, value: value, loaded: true}
+		return nil
+	}
+	return l.decodeLink(raw)
+}
+
+func (l *LinkOne[T]) decodeLink(v any) error {
+	if row, ok := v.(map[string]any); ok {
+		var value T
+		if err := decodeValue(row, &value); err != nil {
+			return err
+		}
+		id, _ := recordIDOf(row["id"])
+		*l = LinkOne[T]{id: id, value: value, loaded: true}
+		return nil
+	}
+	if v == nil {
+		*l = LinkOne[T]{}
+		return nil
+	}
+	id, ok := recordIDOf(v)
+	if !ok {
+		return fmt.Errorf("%w: cannot link %T", ErrInvalidID, v)
+	}
+	*l = LinkOne[T]{id: id}
+	return nil
+}
+
+// LinkSelf is a self-referential relation; it behaves like LinkOne.
+type LinkSelf[T any] struct {
+	LinkOne[T]
+}
+
+func NewLinkSelf[T any](id models.RecordID) LinkSelf[T] {
+	return LinkSelf[T]{LinkOne: NewLinkOne[T](id)}
+}
+
+// LinkMany is a one-to-many relation holding record IDs and, once fetched,
+// the linked records.
+type LinkMany[T any] struct {
+	ids    []models.RecordID
+	values []T
+	loaded bool
+}
+
+// NewLinkMany links to the records ids without loading them.
+func NewLinkMany[T any](ids ...models.RecordID) LinkMany[T] {
+	return LinkMany[T]{ids: ids}
+}
+
+// LoadedLinkMany links to already loaded records.
+func LoadedLinkMany[T any](values ...T) LinkMany[T] {
+	return LinkMany[T]{values: values, loaded: true}
+}
+
+// IsLoaded reports whether the linked records were fetched.
+func (l LinkMany[T]) IsLoaded() bool {
+	return l.loaded
+}
+
+// IsZero reports whether the link holds no records.
+func (l LinkMany[T]) IsZero() bool {
+	return len(l.ids) == 0 && len(l.values) == 0
+}
+
+// IDs returns the linked record IDs.
+func (l LinkMany[T]) IDs() []models.RecordID {
+	if len(l.ids) == 0 && l.loaded {
+		ids := make([]models.RecordID, 0, len(l.values))
+		for _, v := range l.values {
+			if id, ok := recordIDOfValue(v); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return l.ids
+}
+
+// Values returns the linked records, or nil if they were not loaded.
+func (l LinkMany[T]) Values() []T {
+	return l.values
+}
+
+func (l LinkMany[T]) MarshalCBOR() ([]byte, error) {
+	ids := l.IDs()
+	tags := make([]cbor.Tag, len(ids))
+	for i, id := range ids {
+		tags[i] = cbor.Tag{Number: models.TagRecordID, Content: []any{id.Table, id.ID}}
+	}
+	return cbor.Marshal(tags)
+}
+
+func (l *LinkMany[T]) UnmarshalCBOR(data []byte) error {
+	var raw any
+	if err := surrealcbor.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	return l.decodeLink(raw)
+}
+
+func (l LinkMany[T]) MarshalJSON() ([]byte, error) {
+	ids := l.IDs()
+	out := make([]string, len(ids))
+	for i, id := range ids {
+		out[i] = recordLiteral(id.Table, id.ID)
+	}
+	return json.Marshal(out)
+}
+
+func (l *LinkMany[T]) UnmarshalJSON(data []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	links := make([]LinkOne[T], len(items))
+	for i, item := range items {
+		if err := links[i].UnmarshalJSON(item); err != nil {
+			return err
+		}
+	}
+	l.setLinks(links)
+	return nil
+}
+
+func (l *LinkMany[T]) decodeLink(v any) error {
+	if v == nil {
+		*l = LinkMany[T]{}
+		return nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return fmt.Errorf("%w: cannot link %T as many", ErrInvalidID, v)
+	}
+	links := make([]LinkOne[T], len(items))
+	for i, item := range items {
+		if err := links[i].decodeLink(item); err != nil {
+			return err
+		}
+	}
+	l.setLinks(links)
+	return nil
+}
+
+// setLinks stores decoded items; the relation counts as loaded only when
+// every item was a record object.
+func (l *LinkMany[T]) setLinks(links []LinkOne[T]) {
+	out := LinkMany[T]{loaded: len(links) > 0}
+	for _, link := range links {
+		out.ids = append(out.ids, link.ID())
+		if !link.loaded {
+			out.loaded = false
+		}
+	}
+	if out.loaded {
+		out.values = make([]T, len(links))
+		for i, link := range links {
+			out.values[i] = link.value
+		}
+	}
+	*l = out
+}
+
+// linkDecoder is implemented by link pointers so row decoding can accept
+// either record IDs or fetched records.
+type linkDecoder interface {
+	decodeLink(v any) error
+}
+
+// recordIDOf converts driver record IDs, orm IDs and "table:key" strings.
+func recordIDOf(v any) (models.RecordID, bool) {
+	switch t := v.(type) {
+	case models.RecordID:
+		return t, true
+	case *models.RecordID:
+		if t != nil {
+			return *t, true
+		}
+	case interface{ RecordID() models.RecordID }:
+		return t.RecordID(), true
+	case string:
+		table, key, err := parseRecordLiteral[string](t)
+		if err == nil {
+			return models.NewRecordID(table, key), true
+		}
+	}
+	return models.RecordID{}, false
+}
+
+// recordIDOfValue reads the "id" field of a loaded record.
+func recordIDOfValue(v any) (models.RecordID, bool) {
+	if enc, ok := v.(MapEncoder); ok {
+		return recordIDOf(enc.ToMap()["id"])
+	}
+	return models.RecordID{}, false
+}
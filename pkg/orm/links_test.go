@@ -0,0 +1,129 @@
+package orm
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+)
+
+type linkAuthor struct {
+	ID   SimpleID[idUser] `json:"id"`
+	Name string           `json:"name"`
+}
+
+func (a linkAuthor) ToMap() map[string]any {
+	return map[string]any{"id": a.ID, "name": a.Name}
+}
+
+func (a *linkAuthor) FromMap(row map[string]any) error {
+	if err := DecodeField(row, "id", &a.ID); err != nil {
+		return err
+	}
+	return DecodeField(row, "name", &a.Name)
+}
+
+type linkPost struct {
+	Author  LinkOne[linkAuthor]
+	Editors LinkMany[linkAuthor]
+	Parent  LinkSelf[linkAuthor]
+}
+
+func (p *linkPost) FromMap(row map[string]any) error {
+	if err := DecodeField(row, "author", &p.Author); err != nil {
+		return err
+	}
+	if err := DecodeField(row, "editors", &p.Editors); err != nil {
+		return err
+	}
+	return DecodeField(row, "parent", &p.Parent)
+}
+
+func TestLinksDecodeIDs(t *testing.T) {
+	row := map[string]any{
+		"author":  models.NewRecordID("user", "ann"),
+		"editors": []any{models.NewRecordID("user", "bob"), "user:cy"},
+		"parent":  nil,
+	}
+	post, err := DecodeRow[linkPost](row)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if post.Author.IsLoaded() || post.Author.ID() != models.NewRecordID("user", "ann") {
+		t.Fatalf("unexpected author: %+v", post.Author)
+	}
+	if post.Editors.IsLoaded() || len(post.Editors.IDs()) != 2 || post.Editors.IDs()[1] != models.NewRecordID("user", "cy") {
+		t.Fatalf("unexpected editors: %+v", post.Editors)
+	}
+	if !post.Parent.IsZero() {
+		t.Fatalf("expected empty parent")
+	}
+}
+
+func TestLinksDecodeFetched(t *testing.T) {
+	row := map[string]any{
+		"author":  map[string]any{"id": models.NewRecordID("user", "ann"), "name": "Ann"},
+		"editors": []any{map[string]any{"id": models.NewRecordID("user", "bob"), "name": "Bob"}},
+	}
+	post, err := DecodeRow[linkPost](row)
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !post.Author.IsLoaded() || post.Author.Value().Name != "Ann" || post.Author.ID().Table != "user" {
+		t.Fatalf("unexpected author: %+v", post.Author)
+	}
+	if !post.Editors.IsLoaded() || post.Editors.Values()[0].Name != "Bob" {
+		t.Fatalf("unexpected editors: %+v", post.Editors)
+	}
+}
+
+func TestLinkCBORWritesRecordIDs(t *testing.T) {
+	link := LoadedLinkOne(linkAuthor{ID: NewSimpleID[idUser]("ann"), Name: "Ann"})
+	data, err := surrealcbor.Marshal(link)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var rid models.RecordID
+	if err := surrealcbor.Unmarshal(data, &rid); err != nil || rid != models.NewRecordID("user", "ann") {
+		t.Fatalf("unexpected record id: %+v %v", rid, err)
+	}
+
+	many := NewLinkMany[linkAuthor](models.NewRecordID("user", "a"), models.NewRecordID("user", "b"))
+	data, err = surrealcbor.Marshal(many)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var back LinkMany[linkAuthor]
+	if err := surrealcbor.Unmarshal(data, &back); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !reflect.DeepEqual(back.IDs(), many.IDs()) {
+		t.Fatalf("unexpected round trip: %+v", back.IDs())
+	}
+}
+
+func TestLinkJSONAcceptsBothShapes(t *testing.T) {
+	var out struct {
+		Author  LinkOne[linkAuthor]  `json:"author"`
+		Editors LinkMany[linkAuthor] `json:"editors"`
+	}
+	data := `{"author":{"id":"user:ann","name":"Ann"},"editors":["user:bob"]}`
+	if err := json.Unmarshal([]byte(data), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !out.Author.IsLoaded() || out.Author.Value().Name != "Ann" || out.Author.ID() != models.NewRecordID("user", "ann") {
+		t.Fatalf("unexpected author: %+v", out.Author)
+	}
+	if out.Editors.IsLoaded() || out.Editors.IDs()[0] != models.NewRecordID("user", "bob") {
+		t.Fatalf("unexpected editors: %+v", out.Editors)
+	}
+	encoded, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(encoded) != `{"author":"user:ann","editors":["user:bob"]}` {
+		t.Fatalf("unexpected json: %s", encoded)
+	}
+}
@@ -0,0 +1,111 @@
+package orm
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Redacted replaces argument values hidden by a Redactor.
+const Redacted = "[REDACTED]"
+
+// Redactor maps a bound argument to the value that may be logged.
+type Redactor func(name string, value any) any
+
+// RedactAll hides every argument value.
+func RedactAll(string, any) any {
+	return Redacted
+}
+
+// RedactArgs hides the named arguments and keeps the rest.
+func RedactArgs(names ...string) Redactor {
+	hidden := make(map[string]bool, len(names))
+	for _, n := range names {
+		hidden[n] = true
+	}
+	return func(name string, value any) any {
+		if hidden[name] {
+			return Redacted
+		}
+		return value
+	}
+}
+
+// LogOptions configures NewSlogInterceptor.
+type LogOptions struct {
+	// Level is used for successful queries (the zero value is Info).
+	Level slog.Level
+	// SlowThreshold, when set, logs queries at least this slow at Warn.
+	SlowThreshold time.Duration
+	// Redact filters argument values; nil hides them all.
+	Redact Redactor
+}
+
+// NewSlogInterceptor logs every query with its text, redacted arguments,
+// duration, row count and error. Failed queries log at Error.
+func NewSlogInterceptor(logger *slog.Logger, opts LogOptions) Interceptor {
+	if opts.Redact == nil {
+		opts.Redact = RedactAll
+	}
+	return InterceptorFuncs{After: func(ctx context.Context, info *QueryInfo) {
+		level, msg := opts.Level, "surreal query"
+		switch {
+		case info.Err != nil:
+			level, msg = slog.LevelError, "surreal query failed"
+		case opts.SlowThreshold > 0 && info.Duration >= opts.SlowThreshold:
+			level, msg = slog.LevelWarn, "slow surreal query"
+		}
+		if !logger.Enabled(ctx, level) {
+			return
+		}
+		attrs := []slog.Attr{
+			slog.String("query", info.Text),
+			slog.Duration("duration", info.Duration),
+			slog.Int("rows", info.Rows),
+		}
+		if args := redactArgs(info.Args, opts.Redact); len(args) > 0 {
+			attrs = append(attrs, slog.Group("args", args...))
+		}
+		if info.Err != nil {
+			attrs = append(attrs, slog.Any("error", info.Err))
+		}
+		logger.LogAttrs(ctx, level, msg, attrs...)
+	}}
+}
+
+func redactArgs(args map[string]any, redact Redactor) []any {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]any, 0, len(names))
+	for _, name := range names {
+		out = append(out, slog.Any(name, redact(name, args[name])))
+	}
+	return out
+}
+
+// Metrics receives query measurements. Adapt it to Prometheus,
+// OpenTelemetry or any other backend.
+type Metrics interface {
+	ObserveQuery(op string, duration time.Duration, rows int, err error)
+}
+
+// NewMetricsInterceptor reports every query to m. The op is the leading
+// keyword of the query text, e.g. "SELECT" or "BEGIN".
+func NewMetricsInterceptor(m Metrics) Interceptor {
+	return InterceptorFuncs{After: func(_ context.Context, info *QueryInfo) {
+		m.ObserveQuery(queryOp(info.Text), info.Duration, info.Rows, info.Err)
+	}}
+}
+
+func queryOp(text string) string {
+	text = strings.TrimSpace(text)
+	if idx := strings.IndexAny(text, " ;\n\t"); idx >= 0 {
+		text = text[:idx]
+	}
+	return strings.ToUpper(text)
+}
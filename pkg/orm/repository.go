@@ -0,0 +1,355 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// ErrNotFound is returned when a statement expected a record but got none.
+var ErrNotFound = errors.New("orm: record not found")
+
+// ErrStaleRecord is returned when a versioned update matched no row because
+// the record changed since it was loaded.
+var ErrStaleRecord = errors.New("orm: stale record")
+
+// SoftDeleter is implemented by models annotated with soft_delete=<field>.
+type SoftDeleter interface {
+	SoftDeleteField() string
+}
+
+// Versioned is implemented by models with an `orm:field version` field.
+type Versioned interface {
+	VersionField() string
+}
+
+// QueryOption adjusts repository selects.
+type QueryOption func(*queryConfig)
+
+type queryConfig struct {
+	withDeleted bool
+	fetch       []qb.Node
+	projections []qb.Node
+	pageSize    int
+}
+
+// WithDeleted includes soft-deleted rows in selects.
+func WithDeleted() QueryOption {
+	return func(c *queryConfig) {
+		c.withDeleted = true
+	}
+}
+
+// With eager-loads link fields with FETCH, e.g. With(schema.Author). The
+// fetched records decode into the LinkOne/LinkMany fields as loaded values.
+func With(fields ...qb.FieldRef) QueryOption {
+	return func(c *queryConfig) {
+		for _, f := range fields {
+			c.fetch = append(c.fetch, f)
+		}
+	}
+}
+
+// WithOutgoing loads the records the selected rows reach through edge E
+// (->edge->out) into the field alias.
+func WithOutgoing[E Edge](alias string) QueryOption {
+	var edge E
+	path := "->" + edge.Table().Name() + "->" + edge.EdgeOut().Name() + ".*"
+	return withProjection(path, alias)
+}
+
+// WithIncoming loads the records linking to the selected rows through edge E
+// (<-edge<-in) into the field alias.
+func WithIncoming[E Edge](alias string) QueryOption {
+	var edge E
+	path := "<-" + edge.Table().Name() + "<-" + edge.EdgeIn().Name() + ".*"
+	return withProjection(path, alias)
+}
+
+func withProjection(path, alias string) QueryOption {
+	return func(c *queryConfig) {
+		c.projections = append(c.projections, qb.As(qb.Raw(path), alias))
+	}
+}
+
+func newQueryConfig(opts []QueryOption) queryConfig {
+	var cfg queryConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Repository runs typed statements for a model.
+type Repository[T Model] struct {
+	Exec       *Executor
+	table      qb.Table
+	softDelete string
+	version    string
+}
+
+func NewRepository[T Model](exec *Executor) *Repository[T] {
+	var zero T
+	r := &Repository[T]{Exec: exec, table: zero.Table()}
+	if sd, ok := any(zero).(SoftDeleter); ok {
+		r.softDelete = sd.SoftDeleteField()
+	}
+	if v, ok := any(zero).(Versioned); ok {
+		r.version = v.VersionField()
+	}
+	return r
+}
+
+// Table returns the model table.
+func (r *Repository[T]) Table() qb.Table {
+	return r.table
+}
+
+// Select builds a SELECT over the model table. Soft-deleted rows are
+// excluded unless WithDeleted is given; With, WithOutgoing and WithIncoming
+// add eager loading.
+func (r *Repository[T]) Select(cond qb.Condition, opts ...QueryOption) *qb.SelectBuilder {
+	return r.selectFrom(r.table, cond, opts)
+}
+
+func (r *Repository[T]) selectFrom(target qb.Node, cond qb.Condition, opts []QueryOption) *qb.SelectBuilder {
+	cfg := newQueryConfig(opts)
+	if r.softDelete != "" && !cfg.withDeleted {
+		cond = andCond(cond, r.notDeleted())
+	}
+	var projections []qb.Projection
+	if len(cfg.projections) > 0 {
+		projections = append([]qb.Projection{qb.All}, cfg.projections...)
+	}
+	stmt := qb.Select(projections...).From(target)
+	if !cond.IsZero() {
+		stmt.Where(cond)
+	}
+	if len(cfg.fetch) > 0 {
+		stmt.Fetch(cfg.fetch...)
+	}
+	return stmt
+}
+
+// Find selects the rows matching cond.
+func (r *Repository[T]) Find(ctx context.Context, cond qb.Condition, opts ...QueryOption) ([]T, error) {
+	return r.Fetch(ctx, r.Select(cond, opts...))
+}
+
+// Fetch runs any statement and decodes its rows as T, running AfterLoad
+// hooks.
+func (r *Repository[T]) Fetch(ctx context.Context, stmt qb.Statement) ([]T, error) {
+	rows, err := r.Exec.Exec(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	return loadRows[T](ctx, rows)
+}
+
+// Create inserts value and returns the stored record, running the
+// BeforeCreate and AfterCreate hooks.
+func (r *Repository[T]) Create(ctx context.Context, value T) (T, error) {
+	var zero T
+	if err := callHook(&value, func(h BeforeCreateHook) error { return h.BeforeCreate(ctx) }); err != nil {
+		return zero, err
+	}
+	payload, err := EncodeModel(value)
+	if err != nil {
+		return zero, err
+	}
+	created, err := r.one(ctx, qb.Create(r.table).Content(payload))
+	if err != nil {
+		return zero, err
+	}
+	if err := callHook(&created, func(h AfterCreateHook) error { return h.AfterCreate(ctx) }); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// Update merges value into the record identified by id, running the
+// BeforeUpdate and AfterUpdate hooks. Versioned models are only updated if
+// the stored version still equals value's; otherwise ErrStaleRecord is
+// returned.
+func (r *Repository[T]) Update(ctx context.Context, id qb.Node, value T) (T, error) {
+	var zero T
+	if err := callHook(&value, func(h BeforeUpdateHook) error { return h.BeforeUpdate(ctx) }); err != nil {
+		return zero, err
+	}
+	payload, err := EncodeModel(value)
+	if err != nil {
+		return zero, err
+	}
+	delete(payload, "id")
+	var stmt qb.Statement = qb.Update(id).Merge(payload)
+	if r.version != "" {
+		stmt = r.versionedUpdate(id, payload)
+	}
+	updated, err := r.one(ctx, stmt)
+	if r.version != "" && errors.Is(err, ErrNotFound) {
+		return zero, fmt.Errorf("%w: %s", ErrStaleRecord, qb.Build(id).Text)
+	}
+	if err != nil {
+		return zero, err
+	}
+	if err := callHook(&updated, func(h AfterUpdateHook) error { return h.AfterUpdate(ctx) }); err != nil {
+		return updated, err
+	}
+	return updated, nil
+}
+
+// Load selects the record identified by id and starts tracking its
+// changes for Save. Options apply as in Select: a soft-deleted record is
+// ErrNotFound unless WithDeleted is given, and With, WithOutgoing and
+// WithIncoming add eager loading.
+func (r *Repository[T]) Load(ctx context.Context, id qb.Node, opts ...QueryOption) (*Tracked[T], error) {
+	value, err := r.one(ctx, r.byID(id, opts...))
+	if err != nil {
+		return nil, err
+	}
+	return Track(id, value)
+}
+
+// Save merges only the fields of t that changed since it was loaded and
+// returns their names. When nothing changed no statement is sent. Hooks
+// and version checks apply as in Update; afterwards t tracks the stored
+// record.
+func (r *Repository[T]) Save(ctx context.Context, t *Tracked[T]) ([]string, error) {
+	changed, err := t.diff()
+	if err != nil || len(changed) == 0 {
+		return nil, err
+	}
+	if err := callHook(&t.Value, func(h BeforeUpdateHook) error { return h.BeforeUpdate(ctx) }); err != nil {
+		return nil, err
+	}
+	if changed, err = t.diff(); err != nil {
+		return nil, err
+	}
+	fields := sortedKeys(changed)
+	var stmt qb.Statement = qb.Update(t.id).Merge(changed)
+	if r.version != "" {
+		payload, err := EncodeModel(t.Value)
+		if err != nil {
+			return nil, err
+		}
+		changed[r.version] = payload[r.version]
+		stmt = r.versionedUpdate(t.id, changed)
+	}
+	updated, err := r.one(ctx, stmt)
+	if r.version != "" && errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrStaleRecord, qb.Build(t.id).Text)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Value = updated
+	if err := t.reset(); err != nil {
+		return fields, err
+	}
+	return fields, callHook(&t.Value, func(h AfterUpdateHook) error { return h.AfterUpdate(ctx) })
+}
+
+// Delete removes the record identified by id. Soft-deleted models are
+// marked instead of removed. A model with a BeforeDelete hook is loaded
+// first and the hook runs on it; one with an AfterDelete hook has the
+// record returned as it was before the delete.
+func (r *Repository[T]) Delete(ctx context.Context, id qb.Node) error {
+	var model T
+	if _, ok := any(&model).(BeforeDeleteHook); ok {
+		loaded, err := r.one(ctx, r.byID(id))
+		if err != nil {
+			return err
+		}
+		if err := callHook(&loaded, func(h BeforeDeleteHook) error { return h.BeforeDelete(ctx, id) }); err != nil {
+			return err
+		}
+	}
+	_, after := any(&model).(AfterDeleteHook)
+	var stmt qb.Statement
+	switch {
+	case r.softDelete != "" && after:
+		stmt = qb.Update(id).Set(r.markDeleted()).Where(r.notDeleted()).Return(qb.Raw("BEFORE"))
+	case r.softDelete != "":
+		stmt = qb.Update(id).Set(r.markDeleted()).Where(r.notDeleted())
+	case after:
+		stmt = qb.Delete(id).Return(qb.Raw("BEFORE"))
+	default:
+		stmt = qb.Delete(id)
+	}
+	rows, err := r.Exec.Exec(ctx, stmt)
+	if err != nil || !after || len(rows) == 0 {
+		return err
+	}
+	deleted, err := loadRow[T](ctx, rows[0])
+	if err != nil {
+		return err
+	}
+	return callHook(&deleted, func(h AfterDeleteHook) error { return h.AfterDelete(ctx, id) })
+}
+
+// DeleteWhere removes, or for soft-deleted models marks, all rows matching
+// cond. Bulk deletes do not run delete hooks.
+func (r *Repository[T]) DeleteWhere(ctx context.Context, cond qb.Condition) error {
+	if r.softDelete != "" {
+		_, err := r.Exec.Exec(ctx, qb.Update(r.table).Set(r.markDeleted()).Where(andCond(cond, r.notDeleted())))
+		return err
+	}
+	stmt := qb.Delete(r.table)
+	if !cond.IsZero() {
+		stmt.Where(cond)
+	}
+	_, err := r.Exec.Exec(ctx, stmt)
+	return err
+}
+
+func (r *Repository[T]) one(ctx context.Context, stmt qb.Statement) (T, error) {
+	var zero T
+	rows, err := r.Exec.Exec(ctx, stmt)
+	if err != nil {
+		return zero, err
+	}
+	if len(rows) == 0 {
+		return zero, ErrNotFound
+	}
+	out, err := loadRow[T](ctx, rows[0])
+	if err != nil {
+		return zero, err
+	}
+	return out, nil
+}
+
+// versionedUpdate sets the payload fields and bumps the version, guarded by
+// the version the caller loaded.
+func (r *Repository[T]) versionedUpdate(id qb.Node, payload map[string]any) qb.Statement {
+	expected := payload[r.version]
+	delete(payload, r.version)
+	keys := sortedKeys(payload)
+	assigns := make([]qb.Assignment, 0, len(keys)+1)
+	for _, k := range keys {
+		assigns = append(assigns, qb.Set(qb.I(k), payload[k]))
+	}
+	assigns = append(assigns, qb.Inc(qb.I(r.version), 1))
+	return qb.Update(id).Set(assigns...).Where(qb.F[any](r.version).Eq(expected))
+}
+
+// byID selects the record identified by id as Select selects the table.
+func (r *Repository[T]) byID(id qb.Node, opts ...QueryOption) *qb.SelectBuilder {
+	return r.selectFrom(id, qb.Condition{}, opts)
+}
+
+func (r *Repository[T]) notDeleted() qb.Condition {
+	return qb.F[any](r.softDelete).Expr().Is(qb.Raw("NONE"))
+}
+
+func (r *Repository[T]) markDeleted() qb.Assignment {
+	return qb.Set(qb.I(r.softDelete), qb.Fn("time::now"))
+}
+
+func andCond(a, b qb.Condition) qb.Condition {
+	if a.IsZero() {
+		return b
+	}
+	return qb.And(a, b)
+}
@@ -0,0 +1,234 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type note struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title"`
+}
+
+func (note) Table() qb.Table { return qb.T("note") }
+
+type archivedNote struct {
+	note
+}
+
+func (archivedNote) SoftDeleteField() string { return "deleted_at" }
+
+func TestRepositoryCRUD(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"id": "note:1", "title": "hello"}}}
+	repo := NewRepository[note](NewExecutor(db))
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, note{Title: "hello"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if created.ID != "note:1" || created.Title != "hello" {
+		t.Fatalf("unexpected created note: %+v", created)
+	}
+	if db.last() != "CREATE note CONTENT $p1" {
+		t.Fatalf("unexpected create query: %s", db.last())
+	}
+
+	found, err := repo.Find(ctx, qb.I("title").Eq("hello"))
+	if err != nil || len(found) != 1 {
+		t.Fatalf("find: %v %v", found, err)
+	}
+	if db.last() != "SELECT * FROM note WHERE title = $p1" {
+		t.Fatalf("unexpected find query: %s", db.last())
+	}
+
+	if _, err := repo.Update(ctx, qb.I("note:1"), note{ID: "note:1", Title: "bye"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if db.last() != "UPDATE note:1 MERGE $p1" {
+		t.Fatalf("unexpected update query: %s", db.last())
+	}
+	if _, ok := db.vars[len(db.vars)-1]["p1"].(map[string]any)["id"]; ok {
+		t.Fatalf("expected id to be dropped from merge payload")
+	}
+
+	if err := repo.Delete(ctx, qb.I("note:1")); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if db.last() != "DELETE note:1" {
+		t.Fatalf("unexpected delete query: %s", db.last())
+	}
+
+	db.rows = nil
+	if _, err := repo.Update(ctx, qb.I("note:2"), note{}); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestRepositorySoftDelete(t *testing.T) {
+	db := &recordingDB{}
+	repo := NewRepository[archivedNote](NewExecutor(db))
+	ctx := context.Background()
+
+	if _, err := repo.Find(ctx, qb.Condition{}); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if db.last() != "SELECT * FROM note WHERE deleted_at IS NONE" {
+		t.Fatalf("unexpected find query: %s", db.last())
+	}
+
+	if _, err := repo.Find(ctx, qb.I("title").Eq("x"), WithDeleted()); err != nil {
+		t.Fatalf("find with deleted: %v", err)
+	}
+	if db.last() != "SELECT * FROM note WHERE title = $p1" {
+		t.Fatalf("unexpected find with deleted query: %s", db.last())
+	}
+
+	if _, err := repo.Load(ctx, qb.I("note:1")); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound loading a deleted note, got %v", err)
+	}
+	if db.last() != "SELECT * FROM note:1 WHERE deleted_at IS NONE" {
+		t.Fatalf("unexpected load query: %s", db.last())
+	}
+	_, _ = repo.Load(ctx, qb.I("note:1"), WithDeleted())
+	if db.last() != "SELECT * FROM note:1" {
+		t.Fatalf("unexpected load with deleted query: %s", db.last())
+	}
+
+	if err := repo.Delete(ctx, qb.I("note:1")); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if db.last() != "UPDATE note:1 SET deleted_at = time::now() WHERE deleted_at IS NONE" {
+		t.Fatalf("unexpected soft delete: %s", db.last())
+	}
+
+	if err := repo.DeleteWhere(ctx, qb.I("title").Eq("x")); err != nil {
+		t.Fatalf("delete where: %v", err)
+	}
+	if db.last() != "UPDATE note SET deleted_at = time::now() WHERE title = $p1 AND deleted_at IS NONE" {
+		t.Fatalf("unexpected soft delete where: %s", db.last())
+	}
+}
+
+type eagerPost struct {
+	linkPost
+	Likers LinkMany[linkAuthor]
+}
+
+func (eagerPost) Table() qb.Table { return qb.T("post") }
+
+func (p *eagerPost) FromMap(row map[string]any) error {
+	if err := p.linkPost.FromMap(row); err != nil {
+		return err
+	}
+	return DecodeField(row, "likers", &p.Likers)
+}
+
+type likes struct{}
+
+func (likes) Table() qb.Table   { return qb.T("likes") }
+func (likes) EdgeIn() qb.Table  { return qb.T("user") }
+func (likes) EdgeOut() qb.Table { return qb.T("post") }
+
+func TestRepositoryEagerLoading(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{
+		"author": map[string]any{"id": "user:ann", "name": "Ann"},
+		"likers": []any{map[string]any{"id": "user:bob", "name": "Bob"}},
+	}}}
+	repo := NewRepository[eagerPost](NewExecutor(db))
+
+	posts, err := repo.Find(context.Background(), qb.Condition{},
+		With(qb.F[LinkOne[linkAuthor]]("author")),
+		WithIncoming[likes]("likers"))
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if db.last() != "SELECT *, <-likes<-user.* AS likers FROM post FETCH author" {
+		t.Fatalf("unexpected query: %s", db.last())
+	}
+	if len(posts) != 1 || !posts[0].Author.IsLoaded() || posts[0].Author.Value().Name != "Ann" {
+		t.Fatalf("expected loaded author: %+v", posts)
+	}
+	if !posts[0].Likers.IsLoaded() || posts[0].Likers.Values()[0].Name != "Bob" {
+		t.Fatalf("expected loaded likers: %+v", posts[0].Likers)
+	}
+
+	if _, err := NewRepository[linkUser](NewExecutor(db)).Find(context.Background(), qb.Condition{}, WithOutgoing[likes]("liked")); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if db.last() != "SELECT *, ->likes->post.* AS liked FROM user" {
+		t.Fatalf("unexpected query: %s", db.last())
+	}
+
+	loaded, err := repo.Load(context.Background(), qb.I("post:1"),
+		With(qb.F[LinkOne[linkAuthor]]("author")),
+		WithIncoming[likes]("likers"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if db.last() != "SELECT *, <-likes<-user.* AS likers FROM post:1 FETCH author" {
+		t.Fatalf("unexpected load query: %s", db.last())
+	}
+	if !loaded.Value.Author.IsLoaded() || !loaded.Value.Likers.IsLoaded() {
+		t.Fatalf("expected eager-loaded links: %+v", loaded.Value)
+	}
+}
+
+type linkUser struct {
+	Name string `json:"name"`
+}
+
+func (linkUser) Table() qb.Table { return qb.T("user") }
+
+type versionedNote struct {
+	ID      string `json:"id,omitempty"`
+	Title   string `json:"title"`
+	Version int    `json:"version"`
+}
+
+func (versionedNote) Table() qb.Table      { return qb.T("note") }
+func (versionedNote) VersionField() string { return "version" }
+
+func TestRepositoryVersionedUpdate(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"id": "note:1", "title": "new", "version": 4}}}
+	repo := NewRepository[versionedNote](NewExecutor(db))
+	ctx := context.Background()
+
+	updated, err := repo.Update(ctx, qb.I("note:1"), versionedNote{ID: "note:1", Title: "new", Version: 3})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Version != 4 {
+		t.Fatalf("unexpected version: %d", updated.Version)
+	}
+	if db.last() != "UPDATE note:1 SET title = $p1, version += $p2 WHERE version = $p3" {
+		t.Fatalf("unexpected update query: %s", db.last())
+	}
+	if got := db.vars[len(db.vars)-1]["p3"]; got != float64(3) {
+		t.Fatalf("unexpected expected version: %#v", got)
+	}
+
+	db.rows = nil
+	_, err = repo.Update(ctx, qb.I("note:1"), versionedNote{Title: "late", Version: 3})
+	if !errors.Is(err, ErrStaleRecord) {
+		t.Fatalf("expected ErrStaleRecord, got %v", err)
+	}
+}
+
+func TestRepositoryVersionedUpdatesBatch(t *testing.T) {
+	repo := NewRepository[versionedNote](NewExecutor(&recordingDB{}))
+	q := qb.Build(qb.QueryChain(
+		repo.versionedUpdate(qb.I("note:1"), map[string]any{"title": "a", "version": 3}),
+		repo.versionedUpdate(qb.I("note:2"), map[string]any{"title": "b", "version": 7}),
+	))
+	want := "UPDATE note:1 SET title = $p1, version += $p2 WHERE version = $p3; UPDATE note:2 SET title = $p4, version += $p5 WHERE version = $p6"
+	if q.Text != want {
+		t.Fatalf("unexpected chain:\n%s\nexpected:\n%s", q.Text, want)
+	}
+	if q.Args["p3"] != 3 || q.Args["p6"] != 7 {
+		t.Fatalf("expected each update to keep its version, got %v", q.Args)
+	}
+}
@@ -0,0 +1,110 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// RetryPolicy replays failed queries. Only idempotent statements are
+// replayed: SELECTs, chains wrapped in BEGIN ... COMMIT (a failed
+// transaction is rolled back as a whole, so UnitOfWork commits qualify) and
+// statements run with a context from WithRetryable.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts; 0 or 1 disables retries.
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles per attempt.
+	BaseDelay time.Duration
+	// MaxDelay caps the delay; 0 means no cap.
+	MaxDelay time.Duration
+	// Retryable decides whether an error is worth retrying. Nil retries
+	// ErrTransactionConflict only.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy retries transaction conflicts up to three times.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 4, BaseDelay: 20 * time.Millisecond, MaxDelay: time.Second}
+}
+
+// WithRetry sets the executor retry policy.
+func WithRetry(p RetryPolicy) ExecutorOption {
+	return func(e *Executor) {
+		e.Retry = p
+	}
+}
+
+type retryableKey struct{}
+
+// WithRetryable marks statements executed with ctx as safe to replay even
+// if they write.
+func WithRetryable(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryableKey{}, true)
+}
+
+func (p RetryPolicy) retryable(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return errors.Is(err, ErrTransactionConflict)
+}
+
+// delay returns the jittered backoff before retry number attempt (1-based):
+// a random duration in [d/2, d] where d = BaseDelay * 2^(attempt-1).
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + rand.N(d-half+1)
+}
+
+// run calls fn until it succeeds, fails with a non-retryable error, runs out
+// of attempts or would outlive the context deadline.
+func (p RetryPolicy) run(ctx context.Context, stmt qb.Statement, fn func() error) error {
+	err := fn()
+	if p.MaxAttempts <= 1 || !canReplay(ctx, stmt) {
+		return err
+	}
+	for attempt := 1; attempt < p.MaxAttempts && err != nil && p.retryable(err); attempt++ {
+		wait := p.delay(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+			return err
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		err = fn()
+	}
+	return err
+}
+
+func canReplay(ctx context.Context, stmt qb.Statement) bool {
+	if marked, _ := ctx.Value(retryableKey{}).(bool); marked {
+		return true
+	}
+	switch s := stmt.(type) {
+	case *qb.SelectBuilder:
+		return true
+	case qb.Chain:
+		n := len(s.Statements)
+		return n >= 2 &&
+			qb.Build(s.Statements[0]).Text == qb.Build(qb.BeginTransaction()).Text &&
+			qb.Build(s.Statements[n-1]).Text == qb.Build(qb.CommitTransaction()).Text
+	}
+	return false
+}
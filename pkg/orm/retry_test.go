@@ -0,0 +1,132 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+var errConflict = &DBError{Kind: ErrTransactionConflict, Message: "This transaction can be retried"}
+
+// flakyDB fails its first `failures` queries with failErr, or batches with
+// failResults when set.
+type flakyDB struct {
+	batchDB
+	failures    int
+	failErr     error
+	failResults []StatementResult
+}
+
+func (f *flakyDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	f.sql = append(f.sql, sql)
+	if len(f.sql) <= f.failures {
+		return nil, f.failErr
+	}
+	return f.rows, nil
+}
+
+func (f *flakyDB) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]StatementResult, error) {
+	f.sql = append(f.sql, sql)
+	if len(f.sql) <= f.failures {
+		if f.failResults != nil {
+			return f.failResults, nil
+		}
+		return []StatementResult{{Err: f.failErr}}, nil
+	}
+	return f.results, nil
+}
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Microsecond}
+
+func TestRetryIdempotentStatements(t *testing.T) {
+	db := &flakyDB{failures: 2, failErr: errConflict}
+	exec := NewExecutor(db, WithRetry(fastRetry))
+	if _, err := exec.Exec(context.Background(), qb.Select().From(qb.T("note"))); err != nil {
+		t.Fatalf("expected select to succeed after retries, got %v", err)
+	}
+	if len(db.sql) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(db.sql))
+	}
+}
+
+func TestRetrySkipsUnmarkedWrites(t *testing.T) {
+	db := &flakyDB{failures: 1, failErr: errConflict}
+	exec := NewExecutor(db, WithRetry(fastRetry))
+	if _, err := exec.Exec(context.Background(), qb.Delete(qb.I("note:1"))); !errors.Is(err, ErrTransactionConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if len(db.sql) != 1 {
+		t.Fatalf("expected a single attempt, got %d", len(db.sql))
+	}
+
+	db.sql = nil
+	if _, err := exec.Exec(WithRetryable(context.Background()), qb.Delete(qb.I("note:1"))); err != nil {
+		t.Fatalf("expected marked write to be retried, got %v", err)
+	}
+	if len(db.sql) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(db.sql))
+	}
+}
+
+func TestRetryStopsOnOtherErrors(t *testing.T) {
+	db := &flakyDB{failures: 2, failErr: &DBError{Kind: ErrParse, Message: "Parse error"}}
+	exec := NewExecutor(db, WithRetry(fastRetry))
+	if _, err := exec.Exec(context.Background(), qb.Select().From(qb.T("note"))); !errors.Is(err, ErrParse) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if len(db.sql) != 1 {
+		t.Fatalf("expected a single attempt, got %d", len(db.sql))
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	db := &flakyDB{failures: 5, failErr: errConflict}
+	exec := NewExecutor(db, WithRetry(RetryPolicy{MaxAttempts: 5, BaseDelay: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := exec.Exec(ctx, qb.Select().From(qb.T("note"))); !errors.Is(err, ErrTransactionConflict) {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+	if len(db.sql) != 1 {
+		t.Fatalf("expected no retry past the deadline, got %d attempts", len(db.sql))
+	}
+}
+
+func TestRetryUnitOfWork(t *testing.T) {
+	db := &flakyDB{failures: 1, failErr: errConflict}
+	db.results = []StatementResult{{}}
+	uow := NewUnitOfWork(NewExecutor(db, WithRetry(fastRetry)))
+	uow.Delete(qb.I("note:1"))
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("expected commit to succeed after retry, got %v", err)
+	}
+	if len(db.sql) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(db.sql))
+	}
+
+	// The conflicting statement comes after one skipped because of it.
+	failed := &DBError{Kind: ErrTransactionFailed, Message: "The query was not executed due to a failed transaction"}
+	db = &flakyDB{failures: 1, failResults: []StatementResult{{Err: failed}, {Err: errConflict}}}
+	db.results = []StatementResult{{}, {}}
+	uow = NewUnitOfWork(NewExecutor(db, WithRetry(fastRetry)))
+	uow.Delete(qb.I("note:1"))
+	uow.Delete(qb.I("note:2"))
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("expected multi-statement commit to succeed after retry, got %v", err)
+	}
+	if len(db.sql) != 2 {
+		t.Fatalf("expected the conflict to be retried, got %d attempts", len(db.sql))
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10 * time.Millisecond, MaxDelay: 25 * time.Millisecond}
+	for attempt, max := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 5: 25 * time.Millisecond} {
+		if d := p.delay(attempt); d < max/2 || d > max {
+			t.Fatalf("attempt %d: delay %v outside [%v, %v]", attempt, d, max/2, max)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package orm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type statementKey struct{}
+type primaryKey struct{}
+
+// withStatement records the statement being executed so DBs such as Router
+// can inspect it.
+func withStatement(ctx context.Context, stmt qb.Statement) context.Context {
+	return context.WithValue(ctx, statementKey{}, stmt)
+}
+
+// StatementFrom returns the qb statement an Executor is running with ctx.
+func StatementFrom(ctx context.Context) (qb.Statement, bool) {
+	stmt, ok := ctx.Value(statementKey{}).(qb.Statement)
+	return stmt, ok
+}
+
+// WithPrimary makes a Router send queries run with ctx to the primary,
+// e.g. to read back a write without replication lag.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// IsRead reports whether stmt only reads: a SELECT, INFO or SHOW CHANGES
+// statement, or a chain made only of those.
+func IsRead(stmt qb.Statement) bool {
+	switch s := stmt.(type) {
+	case *qb.SelectBuilder, *qb.InfoStatement, *qb.ShowChangesStatement:
+		return true
+	case qb.Chain:
+		for _, inner := range s.Statements {
+			if !IsRead(inner) {
+				return false
+			}
+		}
+		return len(s.Statements) > 0
+	}
+	return false
+}
+
+// Router is a DB that sends reads to healthy replicas in turn and
+// everything else to the primary. Statements are classified with IsRead
+// from the statement an Executor attaches to the context; raw queries,
+// such as the migrator's, always go to the primary, as do queries run with
+// WithPrimary and, for StickyPrimary after a write, all reads.
+//
+// A replica that fails with a connection error (see IsConnectionError) is
+// marked unhealthy and the query is re-run on the primary; errors the
+// replica answered with are returned as they are. Batches routed to a
+// replica that is not a BatchDB run on the primary. CheckHealth and
+// RunHealthChecks bring replicas back.
+type Router struct {
+	Primary  DB
+	Replicas []DB
+	// StickyPrimary keeps reads on the primary for this long after a write.
+	StickyPrimary time.Duration
+	// HealthCheck probes a replica; by default it runs RETURN true.
+	HealthCheck func(ctx context.Context, db DB) error
+
+	mu        sync.Mutex
+	unhealthy map[int]bool
+	next      int
+	lastWrite time.Time
+}
+
+func NewRouter(primary DB, replicas ...DB) *Router {
+	return &Router{Primary: primary, Replicas: replicas}
+}
+
+func (r *Router) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	i, db := r.route(ctx)
+	rows, err := db.Query(ctx, sql, vars)
+	if r.failover(ctx, i, err) {
+		return r.Primary.Query(ctx, sql, vars)
+	}
+	return rows, err
+}
+
+func (r *Router) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]StatementResult, error) {
+	i, db := r.route(ctx)
+	if batch, ok := db.(BatchDB); ok {
+		results, err := batch.QueryBatch(ctx, sql, vars)
+		if !r.failover(ctx, i, err) {
+			return results, err
+		}
+	}
+	primary, ok := r.Primary.(BatchDB)
+	if !ok {
+		return nil, ErrBatchUnsupported
+	}
+	return primary.QueryBatch(ctx, sql, vars)
+}
+
+// route picks the DB for a query and returns its replica index, or -1 for
+// the primary.
+func (r *Router) route(ctx context.Context) (int, DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stmt, ok := StatementFrom(ctx)
+	if !ok || !IsRead(stmt) {
+		r.lastWrite = time.Now()
+		return -1, r.Primary
+	}
+	if forced, _ := ctx.Value(primaryKey{}).(bool); forced {
+		return -1, r.Primary
+	}
+	if r.StickyPrimary > 0 && time.Since(r.lastWrite) < r.StickyPrimary {
+		return -1, r.Primary
+	}
+	for range r.Replicas {
+		i := r.next % len(r.Replicas)
+		r.next++
+		if !r.unhealthy[i] {
+			return i, r.Replicas[i]
+		}
+	}
+	return -1, r.Primary
+}
+
+// failover reports whether a query that failed with err on replica i should
+// be re-run on the primary, marking the replica unhealthy if so.
+func (r *Router) failover(ctx context.Context, i int, err error) bool {
+	if i < 0 || ctx.Err() != nil || !IsConnectionError(err) {
+		return false
+	}
+	r.setHealthy(i, false)
+	return true
+}
+
+func (r *Router) setHealthy(i int, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unhealthy == nil {
+		r.unhealthy = map[int]bool{}
+	}
+	r.unhealthy[i] = !ok
+}
+
+// Healthy reports whether replica i is currently used for reads.
+func (r *Router) Healthy(i int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.unhealthy[i]
+}
+
+// CheckHealth probes every replica and updates which ones receive reads.
+func (r *Router) CheckHealth(ctx context.Context) {
+	check := r.HealthCheck
+	if check == nil {
+		check = func(ctx context.Context, db DB) error {
+			_, err := db.Query(ctx, "RETURN true", nil)
+			return err
+		}
+	}
+	for i, db := range r.Replicas {
+		r.setHealthy(i, check(ctx, db) == nil)
+	}
+}
+
+// RunHealthChecks calls CheckHealth every interval until ctx is done.
+func (r *Router) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.CheckHealth(ctx)
+		}
+	}
+}
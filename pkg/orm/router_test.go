@@ -0,0 +1,155 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+func TestIsRead(t *testing.T) {
+	cases := []struct {
+		stmt qb.Statement
+		want bool
+	}{
+		{qb.Select().From(qb.T("note")), true},
+		{qb.InfoForTable(qb.T("note")), true},
+		{qb.QueryChain(qb.Select().From(qb.T("a")), qb.Select().From(qb.T("b"))), true},
+		{qb.Create(qb.T("note")), false},
+		{qb.QueryChain(qb.BeginTransaction(), qb.Select().From(qb.T("a")), qb.CommitTransaction()), false},
+	}
+	for _, c := range cases {
+		if got := IsRead(c.stmt); got != c.want {
+			t.Fatalf("IsRead(%s) = %v, want %v", qb.Build(c.stmt).Text, got, c.want)
+		}
+	}
+}
+
+func TestRouterRoutesByStatement(t *testing.T) {
+	primary, r1, r2 := &recordingDB{}, &recordingDB{}, &recordingDB{}
+	exec := NewExecutor(NewRouter(primary, r1, r2))
+	ctx := context.Background()
+
+	for range 2 {
+		if _, err := exec.Exec(ctx, qb.Select().From(qb.T("note"))); err != nil {
+			t.Fatalf("select: %v", err)
+		}
+	}
+	if _, err := exec.Exec(ctx, qb.Create(qb.T("note"))); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := exec.Exec(WithPrimary(ctx), qb.Select().From(qb.T("note"))); err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if _, err := exec.DB.Query(ctx, "SELECT * FROM note", nil); err != nil {
+		t.Fatalf("raw: %v", err)
+	}
+	if len(r1.sql) != 1 || len(r2.sql) != 1 {
+		t.Fatalf("expected reads spread over replicas, got %v %v", r1.sql, r2.sql)
+	}
+	if len(primary.sql) != 3 {
+		t.Fatalf("expected write, forced and raw queries on primary, got %v", primary.sql)
+	}
+}
+
+func TestRouterStickyPrimary(t *testing.T) {
+	primary, replica := &recordingDB{}, &recordingDB{}
+	router := NewRouter(primary, replica)
+	router.StickyPrimary = time.Hour
+	exec := NewExecutor(router)
+	ctx := context.Background()
+
+	_, _ = exec.Exec(ctx, qb.Select().From(qb.T("note")))
+	_, _ = exec.Exec(ctx, qb.Create(qb.T("note")))
+	_, _ = exec.Exec(ctx, qb.Select().From(qb.T("note")))
+	if len(replica.sql) != 1 || len(primary.sql) != 2 {
+		t.Fatalf("expected reads after a write on primary, got %v %v", primary.sql, replica.sql)
+	}
+}
+
+func TestRouterFailoverAndHealthCheck(t *testing.T) {
+	primary := &recordingDB{rows: []map[string]any{{"id": "note:1"}}}
+	replica := &recordingDB{err: &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}}
+	router := NewRouter(primary, replica)
+	exec := NewExecutor(router)
+	ctx := context.Background()
+
+	rows, err := exec.Exec(ctx, qb.Select().From(qb.T("note")))
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected failover to primary, got %v %v", rows, err)
+	}
+	if router.Healthy(0) {
+		t.Fatalf("expected replica to be marked unhealthy")
+	}
+	_, _ = exec.Exec(ctx, qb.Select().From(qb.T("note")))
+	if len(replica.sql) != 1 {
+		t.Fatalf("expected unhealthy replica to be skipped, got %v", replica.sql)
+	}
+
+	replica.err = nil
+	router.CheckHealth(ctx)
+	if !router.Healthy(0) || replica.last() != "RETURN true" {
+		t.Fatalf("expected health check to restore replica, got %v", replica.sql)
+	}
+
+	// Classified errors are the query's fault, not the replica's.
+	replica.err = errors.New("Parse error: unexpected token")
+	if _, err := exec.Exec(ctx, qb.Select().From(qb.T("note"))); err == nil {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+	if !router.Healthy(0) {
+		t.Fatalf("expected replica to stay healthy on query errors")
+	}
+
+	// So are errors that say nothing about the connection.
+	replica.err = errors.New("Send: error marshaling result: cbor: unsupported type")
+	if _, err := exec.Exec(ctx, qb.Select().From(qb.T("note"))); err == nil {
+		t.Fatalf("expected the encoding error, got %v", err)
+	}
+	if !router.Healthy(0) {
+		t.Fatalf("expected replica to stay healthy on unclassified errors")
+	}
+}
+
+func TestRouterBatchFallsBackToPrimary(t *testing.T) {
+	primary := &batchDB{results: []StatementResult{{Rows: []map[string]any{{"id": "note:1"}}}}}
+	replica := &recordingDB{}
+	exec := NewExecutor(NewRouter(primary, replica))
+
+	results, err := exec.ExecBatch(context.Background(), qb.Select().From(qb.T("note")))
+	if err != nil || len(results) != 1 {
+		t.Fatalf("expected the batch to run on the primary, got %v %v", results, err)
+	}
+	if len(replica.sql) != 0 || len(primary.sql) != 1 {
+		t.Fatalf("expected only the primary to be queried, got %v %v", primary.sql, replica.sql)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{io.EOF, true},
+		{fmt.Errorf("send: %w", io.ErrUnexpectedEOF), true},
+		{net.ErrClosed, true},
+		{&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{errors.New("connection is closed"), true},
+		{fmt.Errorf("query: %w", errors.New("response channel closed")), true},
+		{errors.New("websocket: close 1006 (abnormal closure): unexpected EOF"), true},
+		{nil, false},
+		{errors.New("Send: error marshaling result: cbor: unsupported type"), false},
+		{errors.New("There was a problem with the database: the connection is closed by the server"), false},
+		{context.Canceled, false},
+	}
+	for _, c := range cases {
+		if got := IsConnectionError(c.err); got != c.want {
+			t.Fatalf("IsConnectionError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// ErrTenantRequired is returned when a statement touches a tenant-scoped
+// table but the context carries no tenant.
+var ErrTenantRequired = errors.New("orm: tenant required")
+
+// ErrTenantUnresolved is returned by Tenancy when a statement target, such
+// as a parameter, a raw subquery or a graph expression, does not name a
+// table, so whether it needs scoping cannot be told.
+var ErrTenantUnresolved = errors.New("orm: cannot resolve table to scope by tenant")
+
+type tenantKey struct{}
+
+// WithTenant returns a context scoped to the given tenant.
+func WithTenant(ctx context.Context, tenant any) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom returns the tenant stored in ctx.
+func TenantFrom(ctx context.Context) (any, bool) {
+	v := ctx.Value(tenantKey{})
+	return v, v != nil
+}
+
+type unscopedRawKey struct{}
+
+// WithUnscopedRaw returns a context in which Tenancy passes raw statements
+// through untouched; the caller takes over scoping them.
+func WithUnscopedRaw(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unscopedRawKey{}, true)
+}
+
+// Tenancy scopes statements on tenant-owned tables. SELECT, UPDATE and
+// DELETE get a "field = $tenant" filter; CREATE, INSERT and RELATE get the
+// field set, replacing any value the caller gave, as do the CONTENT and
+// MERGE data of an UPDATE; its SET loses assignments to the field.
+// Statements whose target is not a table, a record id or a qb.Subquery
+// (scoped on its own) are refused with ErrTenantUnresolved, and so is raw
+// SurrealQL in statement position unless ctx comes from WithUnscopedRaw.
+type Tenancy struct {
+	// Tables maps table name to its tenant field, as generated by TenantTables().
+	Tables map[string]string
+	// Param is the bound parameter name, "tenant" by default.
+	Param string
+}
+
+func NewTenancy(tables map[string]string) *Tenancy {
+	return &Tenancy{Tables: tables, Param: "tenant"}
+}
+
+// Rewriter returns the tenancy as an Executor rewriter.
+func (t *Tenancy) Rewriter() Rewriter {
+	return t.Rewrite
+}
+
+// Rewrite scopes every statement in the tree to the tenant stored in ctx.
+func (t *Tenancy) Rewrite(ctx context.Context, stmt qb.Statement) (qb.Statement, error) {
+	tenant, hasTenant := TenantFrom(ctx)
+	if len(t.Tables) > 0 && ctx.Value(unscopedRawKey{}) == nil && hasRawStatement(stmt) {
+		return nil, fmt.Errorf("%w: raw statement", ErrTenantUnresolved)
+	}
+	var rerr error
+	out := qb.RewriteStatement(stmt, func(n qb.Node) qb.Node {
+		if rerr != nil {
+			return n
+		}
+		table, fields, err := t.scope(n)
+		if err != nil {
+			rerr = err
+			return n
+		}
+		if len(fields) == 0 {
+			return n
+		}
+		if !hasTenant {
+			rerr = fmt.Errorf("%w for table %s", ErrTenantRequired, table)
+			return n
+		}
+		next, err := t.apply(n, fields, tenant)
+		if err != nil {
+			rerr = err
+			return n
+		}
+		return next
+	})
+	if rerr != nil {
+		return nil, rerr
+	}
+	return out, nil
+}
+
+// hasRawStatement reports whether raw SurrealQL stands anywhere a statement
+// does: at the top, in a chain, or inside a subquery.
+func hasRawStatement(stmt qb.Statement) bool {
+	found := isRawStatement(stmt)
+	qb.Walk(stmt, func(n qb.Node) bool {
+		switch s := n.(type) {
+		case qb.Chain:
+			found = found || slices.ContainsFunc(s.Statements, isRawStatement)
+		case qb.Subquery:
+			found = found || isRawStatement(s.Stmt)
+		case qb.StmtExpr:
+			found = found || isRawStatement(s.Stmt)
+		case qb.RawStatement:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isRawStatement(stmt qb.Statement) bool {
+	n := qb.Node(stmt)
+	if e, ok := n.(interface{ Node() qb.Node }); ok {
+		n = e.Node()
+	}
+	switch n.(type) {
+	case qb.RawStatement, qb.RawExpr:
+		return true
+	}
+	return false
+}
+
+// scope returns the first scoped table and the tenant fields for n's
+// targets, or ErrTenantUnresolved if a target names no table.
+func (t *Tenancy) scope(n qb.Node) (string, []string, error) {
+	var targets []qb.Node
+	switch s := n.(type) {
+	case *qb.SelectBuilder:
+		targets = s.Targets()
+	case *qb.UpdateBuilder:
+		targets = []qb.Node{s.Target()}
+	case *qb.DeleteBuilder:
+		targets = []qb.Node{s.Target()}
+	case *qb.CreateBuilder:
+		targets = []qb.Node{s.Target()}
+	case *qb.InsertBuilder:
+		targets = []qb.Node{s.Target()}
+	case *qb.RelateBuilder:
+		targets = []qb.Node{s.Edge()}
+	default:
+		return "", nil, nil
+	}
+	if len(t.Tables) == 0 {
+		return "", nil, nil
+	}
+	first := ""
+	seen := map[string]bool{}
+	var fields []string
+	for _, target := range targets {
+		if sub, ok := target.(qb.Subquery); ok {
+			// The inner statement is rewritten on its own.
+			if _, ok := sub.Stmt.(*qb.SelectBuilder); ok {
+				continue
+			}
+		}
+		table := TargetTable(target)
+		if table == "" {
+			return "", nil, fmt.Errorf("%w: %s", ErrTenantUnresolved, qb.Build(target).Text)
+		}
+		field, ok := t.Tables[table]
+		if !ok {
+			continue
+		}
+		if first == "" {
+			first = table
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return first, fields, nil
+}
+
+func (t *Tenancy) param() string {
+	if t.Param == "" {
+		return "tenant"
+	}
+	return t.Param
+}
+
+func (t *Tenancy) filter(existing qb.Condition, fields []string, tenant any) qb.Condition {
+	conds := make([]qb.Condition, 0, len(fields)+1)
+	if !existing.IsZero() {
+		conds = append(conds, existing)
+	}
+	for _, field := range fields {
+		conds = append(conds, qb.F[any](field).Eq(qb.PWith(t.param(), tenant)))
+	}
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return qb.And(conds...)
+}
+
+// assignments drops the caller's assignments to the tenant fields and sets
+// them to the tenant.
+func (t *Tenancy) assignments(existing []qb.Assignment, fields []string, tenant any) []qb.Assignment {
+	out := withoutFields(existing, fields)
+	for _, field := range fields {
+		out = append(out, qb.Set(qb.I(field), qb.PWith(t.param(), tenant)))
+	}
+	return out
+}
+
+func (t *Tenancy) apply(n qb.Node, fields []string, tenant any) (qb.Node, error) {
+	switch s := n.(type) {
+	case *qb.SelectBuilder:
+		return s.Where(t.filter(s.WhereCond(), fields, tenant)), nil
+	case *qb.UpdateBuilder:
+		if data := s.Data(); data != nil {
+			scoped, err := withTenantFields(data, fields, tenant)
+			if err != nil {
+				return nil, err
+			}
+			if s.DataMode() == "MERGE" {
+				s.Merge(scoped)
+			} else {
+				s.Content(scoped)
+			}
+		}
+		// The filter already pins the tenant, so SET only loses the
+		// assignments that would move or unset it.
+		if len(s.Assignments()) > 0 {
+			s.Set(withoutFields(s.Assignments(), fields)...)
+		}
+		return s.Where(t.filter(s.WhereCond(), fields, tenant)), nil
+	case *qb.DeleteBuilder:
+		return s.Where(t.filter(s.WhereCond(), fields, tenant)), nil
+	case *qb.RelateBuilder:
+		return s.Set(t.assignments(s.Assignments(), fields, tenant)...), nil
+	case *qb.CreateBuilder:
+		if s.ContentValue() == nil {
+			return s.Set(t.assignments(s.Assignments(), fields, tenant)...), nil
+		}
+		content, err := withTenantFields(s.ContentValue(), fields, tenant)
+		if err != nil {
+			return nil, err
+		}
+		return s.Content(content), nil
+	case *qb.InsertBuilder:
+		rows := make([]qb.Node, 0, len(s.Rows()))
+		for _, row := range s.Rows() {
+			scoped, err := withTenantFields(row, fields, tenant)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, qb.V(scoped))
+		}
+		return s.SetRows(rows...), nil
+	}
+	return n, nil
+}
+
+// withTenantFields copies a bound map payload and sets the tenant fields on it.
+func withTenantFields(n qb.Node, fields []string, tenant any) (any, error) {
+	v, ok := n.(qb.Value)
+	if !ok {
+		return nil, fmt.Errorf("orm: cannot scope %T payload to a tenant; bind a map instead", n)
+	}
+	switch payload := v.Val.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(payload)+len(fields))
+		for k, val := range payload {
+			out[k] = val
+		}
+		for _, field := range fields {
+			out[field] = tenant
+		}
+		return out, nil
+	case []map[string]any:
+		out := make([]map[string]any, 0, len(payload))
+		for _, item := range payload {
+			scoped, err := withTenantFields(qb.Value{Val: item}, fields, tenant)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, scoped.(map[string]any))
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("orm: cannot scope %T payload to a tenant; bind a map instead", v.Val)
+}
+
+// withoutFields drops the assignments to fields or to paths below them.
+func withoutFields(assignments []qb.Assignment, fields []string) []qb.Assignment {
+	out := make([]qb.Assignment, 0, len(assignments)+len(fields))
+	for _, a := range assignments {
+		name := strings.TrimSpace(qb.Build(a.Field).Text)
+		if !slices.ContainsFunc(fields, func(field string) bool {
+			return name == field || strings.HasPrefix(name, field+".") || strings.HasPrefix(name, field+"[")
+		}) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// TargetTable returns the table a statement target refers to: a table name,
+// a "table:id" identifier, or anything exposing RecordTable(), inline or
+// bound as a value. It returns "" for anything else, including graph
+// traversals and lists of targets.
+func TargetTable(n qb.Node) string {
+	var text string
+	switch t := n.(type) {
+	case qb.Table:
+		return t.Name()
+	case qb.Ident:
+		text = t.Name
+	case qb.RawExpr:
+		text = strings.TrimSpace(t.Text)
+	case interface{ RecordTable() string }:
+		return t.RecordTable()
+	case qb.Value:
+		switch v := t.Val.(type) {
+		case interface{ RecordTable() string }:
+			return v.RecordTable()
+		case models.RecordID:
+			return v.Table
+		}
+		return ""
+	case interface{ Node() qb.Node }:
+		return TargetTable(t.Node())
+	default:
+		return ""
+	}
+	table, id, hasID := strings.Cut(text, ":")
+	if !tableName.MatchString(table) || hasID && !plainRecordKey(id) {
+		return ""
+	}
+	return table
+}
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// plainRecordKey reports whether id is a single record key, possibly a
+// range, rather than a traversal or a list continuing past the record.
+func plainRecordKey(id string) bool {
+	depth := 0
+	for i, r := range id {
+		switch r {
+		case '[', '{', '(', '⟨':
+			depth++
+		case ']', '}', ')', '⟩':
+			depth--
+		case ',', ' ', '\t', '\n':
+			if depth == 0 {
+				return false
+			}
+		case '-', '<':
+			if depth == 0 && i+1 < len(id) && (id[i+1] == '>' || id[i+1] == '-') {
+				return false
+			}
+		}
+	}
+	return id != "" && depth == 0
+}
@@ -0,0 +1,253 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type recordingDB struct {
+	sql  []string
+	vars []map[string]any
+	rows []map[string]any
+	err  error
+}
+
+func (r *recordingDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	r.sql = append(r.sql, sql)
+	r.vars = append(r.vars, vars)
+	return r.rows, r.err
+}
+
+func (r *recordingDB) last() string {
+	if len(r.sql) == 0 {
+		return ""
+	}
+	return r.sql[len(r.sql)-1]
+}
+
+func TestTenancyScopesStatements(t *testing.T) {
+	tenancy := NewTenancy(map[string]string{"doc": "tenant_id", "shares": "tenant_id"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	cases := []struct {
+		name string
+		stmt qb.Statement
+		want string
+	}{
+		{
+			name: "select",
+			stmt: qb.Select().From(qb.T("doc")).Where(qb.I("title").Eq("x")),
+			want: "SELECT * FROM doc WHERE title = $p1 AND tenant_id = $tenant",
+		},
+		{
+			name: "select without where",
+			stmt: qb.Select().From(qb.T("doc")),
+			want: "SELECT * FROM doc WHERE tenant_id = $tenant",
+		},
+		{
+			name: "update record",
+			stmt: qb.Update(qb.I("doc:1")).Set(qb.Set(qb.I("title"), "y")),
+			want: "UPDATE doc:1 SET title = $p1 WHERE tenant_id = $tenant",
+		},
+		{
+			name: "delete",
+			stmt: qb.Delete(qb.T("doc")).Where(qb.I("old").Eq(true)),
+			want: "DELETE doc WHERE old = $p1 AND tenant_id = $tenant",
+		},
+		{
+			name: "create with set",
+			stmt: qb.Create(qb.T("doc")).Set(qb.Set(qb.I("title"), "x")),
+			want: "CREATE doc SET title = $p1, tenant_id = $tenant",
+		},
+		{
+			name: "relate",
+			stmt: qb.Relate(qb.I("user:1"), qb.T("shares"), qb.I("doc:1")),
+			want: "RELATE user:1 -> shares -> doc:1 SET tenant_id = $tenant",
+		},
+		{
+			name: "unscoped table",
+			stmt: qb.Select().From(qb.T("plan")),
+			want: "SELECT * FROM plan",
+		},
+		{
+			name: "nested subquery",
+			stmt: qb.Select().From(qb.T("plan")).Where(qb.I("id").In(qb.Subquery{Stmt: qb.Select(qb.I("plan")).From(qb.T("doc"))})),
+			want: "SELECT * FROM plan WHERE id IN [(SELECT plan FROM doc WHERE tenant_id = $tenant)]",
+		},
+	}
+	for _, tc := range cases {
+		out, err := tenancy.Rewrite(ctx, tc.stmt)
+		if err != nil {
+			t.Fatalf("%s: rewrite: %v", tc.name, err)
+		}
+		q := qb.Build(out)
+		if q.Text != tc.want {
+			t.Fatalf("%s: unexpected query:\n%s\nexpected:\n%s", tc.name, q.Text, tc.want)
+		}
+		if tc.name != "unscoped table" && q.Args["tenant"] != "acme" {
+			t.Fatalf("%s: expected tenant param, got %v", tc.name, q.Args)
+		}
+	}
+}
+
+func TestTenancyScopesPayloads(t *testing.T) {
+	tenancy := NewTenancy(map[string]string{"doc": "tenant_id"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	payload := map[string]any{"title": "x"}
+	out, err := tenancy.Rewrite(ctx, qb.Create(qb.T("doc")).Content(payload))
+	if err != nil {
+		t.Fatalf("rewrite create: %v", err)
+	}
+	q := qb.Build(out)
+	want := map[string]any{"title": "x", "tenant_id": "acme"}
+	if !reflect.DeepEqual(q.Args["p1"], want) {
+		t.Fatalf("unexpected create content: %v", q.Args["p1"])
+	}
+	if _, ok := payload["tenant_id"]; ok {
+		t.Fatalf("expected caller payload to stay untouched")
+	}
+
+	out, err = tenancy.Rewrite(ctx, qb.Insert(qb.T("doc")).Values([]map[string]any{{"title": "a"}, {"title": "b"}}))
+	if err != nil {
+		t.Fatalf("rewrite insert: %v", err)
+	}
+	rows := qb.Build(out).Args["p1"].([]map[string]any)
+	if len(rows) != 2 || rows[0]["tenant_id"] != "acme" || rows[1]["tenant_id"] != "acme" {
+		t.Fatalf("unexpected insert rows: %v", rows)
+	}
+
+	if _, err := tenancy.Rewrite(ctx, qb.Create(qb.T("doc")).Content(qb.P("data"))); err == nil {
+		t.Fatalf("expected error for opaque content")
+	}
+}
+
+func TestTenancyRequiresTenant(t *testing.T) {
+	db := &recordingDB{}
+	exec := NewExecutor(db, WithRewriter(NewTenancy(map[string]string{"doc": "tenant_id"}).Rewriter()))
+
+	_, err := exec.Exec(context.Background(), qb.Select().From(qb.T("doc")))
+	if !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("expected ErrTenantRequired, got %v", err)
+	}
+	if len(db.sql) != 0 {
+		t.Fatalf("expected statement to be refused before execution")
+	}
+
+	if _, err := exec.Exec(context.Background(), qb.Select().From(qb.T("plan"))); err != nil {
+		t.Fatalf("unscoped select: %v", err)
+	}
+	if _, err := exec.Exec(WithTenant(context.Background(), "acme"), qb.Select().From(qb.T("doc"))); err != nil {
+		t.Fatalf("scoped select: %v", err)
+	}
+	if db.last() != "SELECT * FROM doc WHERE tenant_id = $tenant" {
+		t.Fatalf("unexpected executed query: %s", db.last())
+	}
+}
+
+func TestTenancyFailsClosed(t *testing.T) {
+	tenancy := NewTenancy(map[string]string{"doc": "tenant_id"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	refused := []struct {
+		name string
+		stmt qb.Statement
+	}{
+		{name: "param target", stmt: qb.Select().From(qb.P("t"))},
+		{name: "graph target", stmt: qb.Select().From(qb.Raw("user:1->owns->doc"))},
+		{name: "raw subquery", stmt: qb.Select().From(qb.Raw("(SELECT * FROM doc)"))},
+		{name: "record list", stmt: qb.Update(qb.Raw("doc:1, doc:2")).Set(qb.Set(qb.I("title"), "y"))},
+		{name: "multi-target from", stmt: qb.Select().From(qb.T("plan"), qb.P("t"))},
+		{name: "delete param", stmt: qb.Delete(qb.P("t"))},
+	}
+	for _, tc := range refused {
+		if _, err := tenancy.Rewrite(ctx, tc.stmt); !errors.Is(err, ErrTenantUnresolved) {
+			t.Fatalf("%s: expected ErrTenantUnresolved, got %v", tc.name, err)
+		}
+	}
+
+	out, err := tenancy.Rewrite(ctx, qb.Select().From(qb.Subquery{Stmt: qb.Select().From(qb.T("doc"))}))
+	if err != nil {
+		t.Fatalf("subquery target: %v", err)
+	}
+	if got, want := qb.Build(out).Text, "SELECT * FROM (SELECT * FROM doc WHERE tenant_id = $tenant)"; got != want {
+		t.Fatalf("unexpected subquery target query:\n%s\nexpected:\n%s", got, want)
+	}
+}
+
+func TestTenancyRefusesRawStatements(t *testing.T) {
+	tenancy := NewTenancy(map[string]string{"doc": "tenant_id"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	refused := []struct {
+		name string
+		stmt qb.Statement
+	}{
+		{name: "raw statement", stmt: qb.RawStmt("DELETE doc", nil)},
+		{name: "raw expression", stmt: qb.Raw("DELETE doc")},
+		{name: "chained", stmt: qb.QueryChain(qb.Select().From(qb.T("doc")), qb.RawStmt("DELETE doc", nil))},
+		{name: "subquery", stmt: qb.Select().From(qb.T("doc")).Where(qb.I("id").In(qb.Subquery{Stmt: qb.RawStmt("SELECT id FROM doc", nil)}))},
+	}
+	for _, tc := range refused {
+		if _, err := tenancy.Rewrite(ctx, tc.stmt); !errors.Is(err, ErrTenantUnresolved) {
+			t.Fatalf("%s: expected ErrTenantUnresolved, got %v", tc.name, err)
+		}
+	}
+
+	raw := qb.RawStmt("DELETE doc WHERE tenant_id = $tenant", nil)
+	out, err := tenancy.Rewrite(WithUnscopedRaw(ctx), raw)
+	if err != nil {
+		t.Fatalf("unscoped raw: %v", err)
+	}
+	if got := qb.Build(out).Text; got != raw.Text {
+		t.Fatalf("expected raw statement untouched, got %s", got)
+	}
+	if _, err := NewTenancy(nil).Rewrite(ctx, raw); err != nil {
+		t.Fatalf("expected raw statement without scoped tables to pass, got %v", err)
+	}
+}
+
+func TestTenancyGuardsUpdatePayloads(t *testing.T) {
+	tenancy := NewTenancy(map[string]string{"doc": "tenant_id"})
+	ctx := WithTenant(context.Background(), "acme")
+
+	for _, stmt := range []*qb.UpdateBuilder{
+		qb.Update(qb.I("doc:1")).Content(map[string]any{"title": "y", "tenant_id": "evil"}),
+		qb.Update(qb.I("doc:1")).Merge(map[string]any{"tenant_id": "evil"}),
+	} {
+		out, err := tenancy.Rewrite(ctx, stmt)
+		if err != nil {
+			t.Fatalf("rewrite update: %v", err)
+		}
+		q := qb.Build(out)
+		data, _ := q.Args["p1"].(map[string]any)
+		if data["tenant_id"] != "acme" {
+			t.Fatalf("expected tenant to be forced in %s, got %v", q.Text, q.Args)
+		}
+	}
+
+	out, err := tenancy.Rewrite(ctx, qb.Update(qb.T("doc")).Set(
+		qb.Set(qb.I("title"), "y"),
+		qb.Set(qb.I("tenant_id"), "evil"),
+		qb.Set(qb.I("tenant_id.name"), "evil"),
+	))
+	if err != nil {
+		t.Fatalf("rewrite update set: %v", err)
+	}
+	if got, want := qb.Build(out).Text, "UPDATE doc SET title = $p1 WHERE tenant_id = $tenant"; got != want {
+		t.Fatalf("unexpected update:\n%s\nexpected:\n%s", got, want)
+	}
+
+	out, err = tenancy.Rewrite(ctx, qb.Create(qb.T("doc")).Set(qb.Set(qb.I("tenant_id"), "evil")))
+	if err != nil {
+		t.Fatalf("rewrite create set: %v", err)
+	}
+	q := qb.Build(out)
+	if q.Text != "CREATE doc SET tenant_id = $tenant" || q.Args["tenant"] != "acme" {
+		t.Fatalf("unexpected create: %s %v", q.Text, q.Args)
+	}
+}
@@ -0,0 +1,97 @@
+package orm
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// snapshotMode encodes field values canonically so equal values compare
+// byte for byte regardless of map ordering.
+var snapshotMode, _ = cbor.CanonicalEncOptions().EncMode()
+
+// Tracked holds a loaded model together with a snapshot of its encoded
+// fields, so Repository.Save can send only what changed since the load.
+type Tracked[T Model] struct {
+	Value    T
+	id       qb.Node
+	snapshot map[string][]byte
+}
+
+// Track snapshots value, the current state of the record identified by id.
+func Track[T Model](id qb.Node, value T) (*Tracked[T], error) {
+	t := &Tracked[T]{Value: value, id: id}
+	if err := t.reset(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ID returns the tracked record's identifier.
+func (t *Tracked[T]) ID() qb.Node {
+	return t.id
+}
+
+// Changes lists, sorted, the database fields that differ from the snapshot.
+func (t *Tracked[T]) Changes() ([]string, error) {
+	changed, err := t.diff()
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(changed), nil
+}
+
+// diff returns the changed fields with their new values. Fields no longer
+// present in the payload are reported as nil.
+func (t *Tracked[T]) diff() (map[string]any, error) {
+	payload, err := EncodeModel(t.Value)
+	if err != nil {
+		return nil, err
+	}
+	changed := map[string]any{}
+	for k, v := range payload {
+		if k == "id" {
+			continue
+		}
+		enc, err := snapshotMode.Marshal(v)
+		if err != nil || !bytes.Equal(enc, t.snapshot[k]) {
+			changed[k] = v
+		}
+	}
+	for k := range t.snapshot {
+		if _, ok := payload[k]; !ok {
+			changed[k] = nil
+		}
+	}
+	return changed, nil
+}
+
+func (t *Tracked[T]) reset() error {
+	payload, err := EncodeModel(t.Value)
+	if err != nil {
+		return err
+	}
+	t.snapshot = make(map[string][]byte, len(payload))
+	for k, v := range payload {
+		if k == "id" {
+			continue
+		}
+		// Values that cannot be encoded are left out and so always count
+		// as changed.
+		if enc, err := snapshotMode.Marshal(v); err == nil {
+			t.snapshot[k] = enc
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type trackedDoc struct {
+	Title string
+	Tags  []string
+	Meta  map[string]any
+}
+
+func (trackedDoc) Table() qb.Table { return qb.T("doc") }
+
+func (d trackedDoc) ToMap() map[string]any {
+	return map[string]any{"title": d.Title, "tags": d.Tags, "meta": d.Meta}
+}
+
+func (d *trackedDoc) FromMap(row map[string]any) error {
+	if err := DecodeField(row, "title", &d.Title); err != nil {
+		return err
+	}
+	if err := DecodeSlice(row, "tags", &d.Tags); err != nil {
+		return err
+	}
+	return DecodeField(row, "meta", &d.Meta)
+}
+
+func TestTrackedChanges(t *testing.T) {
+	tracked, err := Track(qb.I("doc:1"), trackedDoc{Title: "a", Tags: []string{"x"}, Meta: map[string]any{"a": 1, "b": 2}})
+	if err != nil {
+		t.Fatalf("track: %v", err)
+	}
+	if changes, _ := tracked.Changes(); len(changes) != 0 {
+		t.Fatalf("expected no changes, got %v", changes)
+	}
+	tracked.Value.Tags[0] = "y"
+	tracked.Value.Meta = map[string]any{"b": 2, "a": 1}
+	changes, err := tracked.Changes()
+	if err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if !reflect.DeepEqual(changes, []string{"tags"}) {
+		t.Fatalf("unexpected changes: %v", changes)
+	}
+}
+
+func TestRepositorySaveMergesChanges(t *testing.T) {
+	db := &recordingDB{rows: []map[string]any{{"id": "doc:1", "title": "a", "tags": []any{"x"}}}}
+	repo := NewRepository[trackedDoc](NewExecutor(db))
+	ctx := context.Background()
+
+	tracked, err := repo.Load(ctx, qb.I("doc:1"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if db.last() != "SELECT * FROM doc:1" {
+		t.Fatalf("unexpected load query: %s", db.last())
+	}
+
+	fields, err := repo.Save(ctx, tracked)
+	if err != nil || fields != nil {
+		t.Fatalf("expected no-op save, got %v %v", fields, err)
+	}
+	if len(db.sql) != 1 {
+		t.Fatalf("expected no statement for unchanged record, got %v", db.sql)
+	}
+
+	tracked.Value.Title = "b"
+	db.rows = []map[string]any{{"id": "doc:1", "title": "b", "tags": []any{"x"}}}
+	fields, err = repo.Save(ctx, tracked)
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if !reflect.DeepEqual(fields, []string{"title"}) {
+		t.Fatalf("unexpected changed fields: %v", fields)
+	}
+	if db.last() != "UPDATE doc:1 MERGE $p1" {
+		t.Fatalf("unexpected save query: %s", db.last())
+	}
+	if merge := db.vars[len(db.vars)-1]["p1"]; !reflect.DeepEqual(merge, map[string]any{"title": "b"}) {
+		t.Fatalf("unexpected merge payload: %#v", merge)
+	}
+	if changes, _ := tracked.Changes(); len(changes) != 0 {
+		t.Fatalf("expected snapshot reset after save, got %v", changes)
+	}
+}
@@ -0,0 +1,121 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+// Op is an operation queued on a UnitOfWork. Rows and Err are filled in by
+// Commit.
+type Op struct {
+	Statement qb.Statement
+	Rows      []map[string]any
+	Err       error
+}
+
+// UnitOfWork collects writes across models and commits them as a single
+// BEGIN ... COMMIT chain in one round trip. Operations are sent as given:
+// repository hooks, soft deletes and version checks do not apply.
+type UnitOfWork struct {
+	exec *Executor
+	ops  []*Op
+}
+
+func NewUnitOfWork(exec *Executor) *UnitOfWork {
+	return &UnitOfWork{exec: exec}
+}
+
+// Add queues an arbitrary statement.
+func (u *UnitOfWork) Add(stmt qb.Statement) *Op {
+	op := &Op{Statement: stmt}
+	u.ops = append(u.ops, op)
+	return op
+}
+
+// Create queues a CREATE of value in its table.
+func (u *UnitOfWork) Create(value Model) (*Op, error) {
+	payload, err := EncodeModel(value)
+	if err != nil {
+		return nil, err
+	}
+	return u.Add(qb.Create(value.Table()).Content(payload)), nil
+}
+
+// Update queues a MERGE of value into the record identified by id.
+func (u *UnitOfWork) Update(id qb.Node, value any) (*Op, error) {
+	payload, err := EncodeModel(value)
+	if err != nil {
+		return nil, err
+	}
+	delete(payload, "id")
+	return u.Add(qb.Update(id).Merge(payload)), nil
+}
+
+// Delete queues a DELETE of the record identified by id.
+func (u *UnitOfWork) Delete(id qb.Node) *Op {
+	return u.Add(qb.Delete(id))
+}
+
+// Relate queues a RELATE from -> edge -> to; see RelateEdge.
+func (u *UnitOfWork) Relate(from models.RecordID, edge qb.Table, to models.RecordID, fields map[string]any) *Op {
+	return u.Add(RelateEdge(from, edge, to, fields))
+}
+
+// DecodeOp decodes the rows of a committed op into T, running AfterLoad
+// hooks, or returns the op's error if it failed.
+func DecodeOp[T any](ctx context.Context, op *Op) ([]T, error) {
+	if op.Err != nil {
+		return nil, op.Err
+	}
+	return loadRows[T](ctx, op.Rows)
+}
+
+// Ops returns the queued operations.
+func (u *UnitOfWork) Ops() []*Op {
+	return u.ops
+}
+
+// Commit runs the queued operations in one transaction and stores each
+// statement's rows and error on its Op. It returns the error of the
+// operation that failed the transaction; the queue is cleared either way.
+func (u *UnitOfWork) Commit(ctx context.Context) error {
+	ops := u.ops
+	u.ops = nil
+	if len(ops) == 0 {
+		return nil
+	}
+	stmts := make([]qb.Statement, 0, len(ops)+2)
+	stmts = append(stmts, qb.BeginTransaction())
+	for _, op := range ops {
+		stmts = append(stmts, op.Statement)
+	}
+	stmts = append(stmts, qb.CommitTransaction())
+	results, err := u.exec.ExecBatch(ctx, stmts...)
+	if err != nil {
+		return err
+	}
+	// Servers may or may not report results for BEGIN and COMMIT.
+	if len(results) == len(stmts) {
+		results = results[1 : len(results)-1]
+	}
+	if len(results) != len(ops) {
+		return fmt.Errorf("orm: unit of work: expected %d results, got %d", len(ops), len(results))
+	}
+	var first error
+	for i, op := range ops {
+		op.Rows, op.Err = results[i].Rows, ClassifyError(results[i].Err)
+		if op.Err == nil {
+			continue
+		}
+		// Prefer the statement that caused the rollback over the ones
+		// skipped because of it.
+		if first == nil || errors.Is(first, ErrTransactionFailed) && !errors.Is(op.Err, ErrTransactionFailed) {
+			first = fmt.Errorf("orm: unit of work op %d: %w", i, op.Err)
+		}
+	}
+	return first
+}
@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+type batchDB struct {
+	recordingDB
+	results []StatementResult
+}
+
+func (b *batchDB) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]StatementResult, error) {
+	b.sql = append(b.sql, sql)
+	b.vars = append(b.vars, vars)
+	return b.results, b.err
+}
+
+func TestUnitOfWorkCommit(t *testing.T) {
+	db := &batchDB{results: []StatementResult{
+		{Rows: []map[string]any{{"id": "note:1", "title": "a"}}},
+		{Rows: []map[string]any{{"id": "note:2", "title": "b"}}},
+		{},
+		{Rows: []map[string]any{{"id": "likes:1"}}},
+	}}
+	uow := NewUnitOfWork(NewExecutor(db))
+	created, err := uow.Create(note{Title: "a"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := uow.Update(qb.I("note:2"), note{Title: "b"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	uow.Delete(qb.I("note:3"))
+	uow.Relate(models.NewRecordID("user", "ann"), qb.T("likes"), models.NewRecordID("note", "1"), nil)
+
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	want := "BEGIN TRANSACTION; CREATE note CONTENT $p1; UPDATE note:2 MERGE $p2; DELETE note:3; RELATE $p3 -> likes -> $p4; COMMIT TRANSACTION"
+	if db.last() != want {
+		t.Fatalf("unexpected query:\n%s\nexpected:\n%s", db.last(), want)
+	}
+	notes, err := DecodeRows[note](created.Rows)
+	if err != nil || len(notes) != 1 || notes[0].ID != "note:1" {
+		t.Fatalf("unexpected create result: %v %v", notes, err)
+	}
+	if len(uow.Ops()) != 0 {
+		t.Fatalf("expected queue to be cleared")
+	}
+}
+
+func TestUnitOfWorkMapsErrors(t *testing.T) {
+	failed := errors.New("duplicate")
+	db := &batchDB{results: []StatementResult{{}, {}, {Err: failed}, {}}}
+	uow := NewUnitOfWork(NewExecutor(db))
+	first := uow.Delete(qb.I("note:1"))
+	second := uow.Delete(qb.I("note:2"))
+	err := uow.Commit(context.Background())
+	if !errors.Is(err, failed) {
+		t.Fatalf("expected op error, got %v", err)
+	}
+	if first.Err != nil || !errors.Is(second.Err, failed) {
+		t.Fatalf("unexpected op errors: %v %v", first.Err, second.Err)
+	}
+}
+
+func TestUnitOfWorkReportsCausalError(t *testing.T) {
+	skipped := errors.New("The query was not executed due to a failed transaction")
+	cause := errors.New("Database index `email` already contains 'a@b.c', with record `user:1`")
+	db := &batchDB{results: []StatementResult{{}, {Err: skipped}, {Err: cause}, {Err: skipped}, {}}}
+	uow := NewUnitOfWork(NewExecutor(db))
+	first := uow.Delete(qb.I("note:1"))
+	uow.Delete(qb.I("note:2"))
+	uow.Delete(qb.I("note:3"))
+	err := uow.Commit(context.Background())
+	if !errors.Is(err, ErrUniqueViolation) || !errors.Is(err, cause) {
+		t.Fatalf("expected the causal error, got %v", err)
+	}
+	if !errors.Is(first.Err, ErrTransactionFailed) {
+		t.Fatalf("expected skipped op to be marked as failed transaction, got %v", first.Err)
+	}
+}
+
+func TestUnitOfWorkRequiresBatchDB(t *testing.T) {
+	uow := NewUnitOfWork(NewExecutor(&recordingDB{}))
+	uow.Delete(qb.I("note:1"))
+	if err := uow.Commit(context.Background()); !errors.Is(err, ErrBatchUnsupported) {
+		t.Fatalf("expected ErrBatchUnsupported, got %v", err)
+	}
+}
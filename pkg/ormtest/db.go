@@ -0,0 +1,153 @@
+// Package ormtest provides an in-memory stand-in for SurrealDB covering the
+// SurrealQL the ORM and migrator emit, so their logic can be tested without
+// a server.
+package ormtest
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+)
+
+// DB is an in-memory SurrealDB fake. It understands DEFINE/REMOVE for
+// tables, fields, indexes, events, accesses and other database-level
+// resources, INFO FOR DB/TABLE, CREATE/SELECT/UPDATE/DELETE with simple
+// WHERE, ORDER BY, LIMIT and START clauses, and BEGIN/COMMIT/CANCEL
+// TRANSACTION. Unsupported statements fail with a parse error.
+//
+// DB implements migrator.DB, orm.DB and orm.BatchDB and is safe for
+// concurrent use.
+type DB struct {
+	mu      sync.Mutex
+	state   *state
+	queries []string
+	rng     *rand.Rand
+}
+
+func New() *DB {
+	return &DB{state: newState(), rng: rand.New(rand.NewPCG(1, 2))}
+}
+
+// Query runs sql and returns the rows of all statements, concatenated like
+// surreal.Adapter.Query. The first statement error is returned instead.
+func (db *DB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	results, err := db.QueryBatch(ctx, sql, vars)
+	if err != nil {
+		return nil, err
+	}
+	var rows []map[string]any
+	for _, r := range results {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		rows = append(rows, r.Rows...)
+	}
+	return rows, nil
+}
+
+// QueryBatch runs sql and returns one result per statement, except for
+// transaction control statements, which, as on a real server, report none.
+// Inside a transaction the first failure rolls back every change since
+// BEGIN and fails the remaining statements.
+func (db *DB) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]orm.StatementResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.queries = append(db.queries, sql)
+	toks, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results  []orm.StatementResult
+		snapshot *state
+		txStart  int
+		txErr    error
+	)
+	for _, stmt := range splitStatements(toks) {
+		p := &parser{src: sql, toks: stmt, vars: vars}
+		switch {
+		case p.keyword("BEGIN"):
+			p.keyword("TRANSACTION")
+			snapshot, txStart, txErr = db.state.clone(), len(results), nil
+			continue
+		case p.keyword("COMMIT"), p.keyword("CANCEL"):
+			if snapshot != nil && stmt[0].isKeyword("CANCEL") {
+				db.state = snapshot
+				failTransaction(results[txStart:], nil, errCancelledTransaction)
+			} else if snapshot != nil && txErr != nil {
+				db.state = snapshot
+				failTransaction(results[txStart:], txErr, errFailedTransaction)
+			}
+			snapshot = nil
+			continue
+		}
+		if txErr != nil {
+			results = append(results, failed(errFailedTransaction))
+			continue
+		}
+		start := time.Now()
+		rows, err := db.exec(p)
+		result := orm.StatementResult{Rows: rows, Status: "OK", Time: time.Since(start).String(), Err: err}
+		if err != nil {
+			result.Status = "ERR"
+		}
+		results = append(results, result)
+		if err != nil && snapshot != nil {
+			txErr = err
+		}
+	}
+	if snapshot != nil && txErr != nil {
+		db.state = snapshot
+		failTransaction(results[txStart:], txErr, errFailedTransaction)
+	}
+	return results, nil
+}
+
+var (
+	errFailedTransaction    = errors.New("The query was not executed due to a failed transaction")
+	errCancelledTransaction = errors.New("The query was not executed due to a cancelled transaction")
+)
+
+// failTransaction replaces the results of a rolled back transaction with
+// err. The statement that caused the failure keeps its own error.
+func failTransaction(results []orm.StatementResult, cause, err error) {
+	for i := range results {
+		if cause == nil || results[i].Err != cause {
+			results[i] = failed(err)
+		}
+	}
+}
+
+func failed(err error) orm.StatementResult {
+	return orm.StatementResult{Status: "ERR", Err: err}
+}
+
+// Queries returns every SQL text received, in order.
+func (db *DB) Queries() []string {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]string(nil), db.queries...)
+}
+
+// Records returns copies of the records stored in table, in insertion
+// order.
+func (db *DB) Records(table string) []map[string]any {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	t := db.state.tables[table]
+	if t == nil {
+		return nil
+	}
+	out := make([]map[string]any, 0, len(t.order))
+	for _, key := range t.order {
+		out = append(out, copyRecord(t.records[key]))
+	}
+	return out
+}
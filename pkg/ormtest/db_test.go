@@ -0,0 +1,191 @@
+package ormtest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+func query(t *testing.T, db *DB, sql string, vars map[string]any) []map[string]any {
+	t.Helper()
+	rows, err := db.Query(context.Background(), sql, vars)
+	if err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+	return rows
+}
+
+func TestDefineAndInfo(t *testing.T) {
+	db := New()
+	query(t, db, `DEFINE TABLE user SCHEMAFULL;
+DEFINE FIELD OVERWRITE name ON TABLE user TYPE string;
+DEFINE FIELD address.city ON user TYPE string;
+DEFINE INDEX email_idx ON TABLE user FIELDS email UNIQUE;
+DEFINE EVENT audit ON TABLE user WHEN $event = "CREATE" THEN { CREATE log; };
+DEFINE ACCESS api ON DATABASE TYPE JWT ALGORITHM HS512 KEY 'secret';`, nil)
+
+	info := query(t, db, "INFO FOR DB", nil)[0]
+	if got := info["tables"].(map[string]any)["user"]; got != "DEFINE TABLE user SCHEMAFULL" {
+		t.Fatalf("unexpected table definition: %v", got)
+	}
+	if _, ok := info["accesses"].(map[string]any)["api"]; !ok {
+		t.Fatalf("expected access definition: %v", info)
+	}
+	table := query(t, db, "INFO FOR TABLE user", nil)[0]
+	fields := table["fields"].(map[string]any)
+	if fields["name"] != "DEFINE FIELD name ON TABLE user TYPE string" || fields["address.city"] == nil {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+	if table["events"].(map[string]any)["audit"] == nil || table["indexes"].(map[string]any)["email_idx"] == nil {
+		t.Fatalf("unexpected table info: %v", table)
+	}
+
+	query(t, db, "REMOVE FIELD name ON TABLE user; REMOVE ACCESS api ON DATABASE", nil)
+	if _, ok := query(t, db, "INFO FOR TABLE user", nil)[0]["fields"].(map[string]any)["name"]; ok {
+		t.Fatalf("expected field to be removed")
+	}
+	_, err := db.Query(context.Background(), "REMOVE TABLE missing", nil)
+	if !errors.Is(orm.ClassifyError(err), orm.ErrNotFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+	query(t, db, "REMOVE TABLE IF EXISTS missing", nil)
+}
+
+func TestRecordStatements(t *testing.T) {
+	db := New()
+	exec := orm.NewExecutor(db)
+	ctx := context.Background()
+	for _, name := range []string{"ann", "bob", "cat"} {
+		age := map[string]int{"ann": 30, "bob": 20, "cat": 40}[name]
+		if _, err := exec.Exec(ctx, qb.Create(qb.T("user")).Content(map[string]any{"id": name, "name": name, "age": age})); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	rows, err := exec.Exec(ctx, qb.Select(qb.I("name")).From(qb.T("user")).
+		Where(qb.F[int]("age").Gte(25)).
+		OrderBy(qb.OrderBy(qb.I("age")).Desc()))
+	if err != nil {
+		t.Fatalf("select: %v", err)
+	}
+	if !reflect.DeepEqual(rows, []map[string]any{{"name": "cat"}, {"name": "ann"}}) {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+
+	rows, err = exec.Exec(ctx, qb.Update(qb.I("user:bob")).Set(qb.Set(qb.I("name"), "robert"), qb.Inc(qb.I("age"), 1)))
+	if err != nil || len(rows) != 1 || rows[0]["name"] != "robert" || rows[0]["age"] != int64(21) {
+		t.Fatalf("unexpected update: %v %v", rows, err)
+	}
+	if id := rows[0]["id"]; id != models.NewRecordID("user", "bob") {
+		t.Fatalf("unexpected id: %#v", id)
+	}
+
+	if _, err := exec.Exec(ctx, qb.Update(qb.T("user")).Merge(map[string]any{"active": true}).Where(qb.I("name").Eq("ann"))); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if _, err := exec.Exec(ctx, qb.Delete(qb.T("user")).Where(qb.I("active").Is(qb.Raw("NONE")))); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	records := db.Records("user")
+	if len(records) != 1 || records[0]["name"] != "ann" || records[0]["active"] != true {
+		t.Fatalf("unexpected records: %v", records)
+	}
+}
+
+type archivedDoc struct {
+	Title string `json:"title"`
+}
+
+func (archivedDoc) Table() qb.Table         { return qb.T("doc") }
+func (archivedDoc) SoftDeleteField() string { return "deleted_at" }
+
+func TestRepositoryLoadSkipsSoftDeleted(t *testing.T) {
+	db := New()
+	query(t, db, "CREATE doc:1 SET title = 'a'", nil)
+	repo := orm.NewRepository[archivedDoc](orm.NewExecutor(db))
+	ctx := context.Background()
+
+	if err := repo.Delete(ctx, qb.I("doc:1")); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.Load(ctx, qb.I("doc:1")); !errors.Is(err, orm.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a soft-deleted record, got %v", err)
+	}
+	tracked, err := repo.Load(ctx, qb.I("doc:1"), orm.WithDeleted())
+	if err != nil || tracked.Value.Title != "a" {
+		t.Fatalf("load with deleted: %+v %v", tracked, err)
+	}
+}
+
+func TestEscapedRecordKeys(t *testing.T) {
+	db := New()
+	key := `a\⟩; DELETE doc; \`
+	id := orm.NewSimpleID[archivedDoc](key)
+	query(t, db, "CREATE "+id.String()+" SET title = 'a'", nil)
+	query(t, db, "CREATE doc:2 SET title = 'b'", nil)
+
+	rows, err := orm.NewExecutor(db).Exec(context.Background(), qb.Select().From(id.Node()))
+	if err != nil || len(rows) != 1 || rows[0]["id"] != models.NewRecordID("doc", key) {
+		t.Fatalf("unexpected rows: %v %v", rows, err)
+	}
+	if len(db.Records("doc")) != 2 {
+		t.Fatalf("expected both records to survive, got %v", db.Records("doc"))
+	}
+}
+
+func TestUniqueIndexAndDuplicateRecords(t *testing.T) {
+	db := New()
+	query(t, db, "DEFINE INDEX email_idx ON TABLE user FIELDS email UNIQUE", nil)
+	query(t, db, "CREATE user:1 SET email = 'a@b.c'", nil)
+
+	_, err := db.Query(context.Background(), "CREATE user:2 SET email = $email", map[string]any{"email": "a@b.c"})
+	if !errors.Is(orm.ClassifyError(err), orm.ErrUniqueViolation) {
+		t.Fatalf("expected unique violation, got %v", err)
+	}
+	_, err = db.Query(context.Background(), "CREATE user:1", nil)
+	if !errors.Is(orm.ClassifyError(err), orm.ErrUniqueViolation) {
+		t.Fatalf("expected duplicate record error, got %v", err)
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	db := New()
+	query(t, db, "DEFINE INDEX email_idx ON TABLE user FIELDS email UNIQUE", nil)
+	uow := orm.NewUnitOfWork(orm.NewExecutor(db))
+	first := uow.Add(qb.Create(qb.T("user")).Set(qb.Set(qb.I("email"), "a")))
+	uow.Add(qb.Create(qb.T("user")).Set(qb.Set(qb.I("email"), "a")))
+	err := uow.Commit(context.Background())
+	if !errors.Is(orm.ClassifyError(err), orm.ErrUniqueViolation) {
+		t.Fatalf("expected unique violation, got %v", err)
+	}
+	if first.Err == nil {
+		t.Fatalf("expected first operation to fail with the transaction")
+	}
+	if len(db.Records("user")) != 0 {
+		t.Fatalf("expected rollback, got %v", db.Records("user"))
+	}
+
+	_, err = db.Query(context.Background(), "BEGIN TRANSACTION; CREATE user:1; CANCEL TRANSACTION", nil)
+	if !errors.Is(orm.ClassifyError(err), orm.ErrTransactionFailed) {
+		t.Fatalf("expected cancelled statement error, got %v", err)
+	}
+	if len(db.Records("user")) != 0 {
+		t.Fatalf("expected cancelled transaction to roll back")
+	}
+	query(t, db, "BEGIN TRANSACTION; CREATE user:1; COMMIT TRANSACTION", nil)
+	if len(db.Records("user")) != 1 {
+		t.Fatalf("expected committed record")
+	}
+}
+
+func TestUnsupportedStatement(t *testing.T) {
+	_, err := New().Query(context.Background(), "LIVE SELECT * FROM user", nil)
+	if !errors.Is(orm.ClassifyError(err), orm.ErrParse) {
+		t.Fatalf("expected parse error, got %v", err)
+	}
+}
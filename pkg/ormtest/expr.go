@@ -0,0 +1,648 @@
+package ormtest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// parser walks the tokens of one statement.
+type parser struct {
+	src  string
+	toks []token
+	i    int
+	vars map[string]any
+}
+
+func (p *parser) done() bool {
+	return p.i >= len(p.toks)
+}
+
+func (p *parser) peek() token {
+	if p.done() {
+		return token{kind: tokPunct, pos: len(p.src), end: len(p.src)}
+	}
+	return p.toks[p.i]
+}
+
+func (p *parser) next() token {
+	t := p.peek()
+	if !p.done() {
+		p.i++
+	}
+	return t
+}
+
+// keyword consumes the keyword sequence kws if it comes next.
+func (p *parser) keyword(kws ...string) bool {
+	for j, kw := range kws {
+		if p.i+j >= len(p.toks) || !p.toks[p.i+j].isKeyword(kw) {
+			return false
+		}
+	}
+	p.i += len(kws)
+	return true
+}
+
+func (p *parser) expectKeyword(kws ...string) error {
+	if !p.keyword(kws...) {
+		return p.errorf("expected %s", strings.Join(kws, " "))
+	}
+	return nil
+}
+
+func (p *parser) punct(text string) bool {
+	if p.peek().is(tokPunct, text) {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.punct(text) {
+		return p.errorf("expected %q", text)
+	}
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	t := p.next()
+	if t.kind != tokIdent {
+		return "", p.errorf("expected identifier, got %q", t.text)
+	}
+	return t.text, nil
+}
+
+// rest returns the remaining source text of the statement.
+func (p *parser) rest() string {
+	if p.done() {
+		return ""
+	}
+	return strings.TrimSpace(p.src[p.peek().pos:p.toks[len(p.toks)-1].end])
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("Parse error: "+format+" near %q", append(args, p.rest())...)
+}
+
+// expr is an evaluated expression; row is the record in scope, if any.
+type expr func(row map[string]any) (any, error)
+
+func constant(v any) expr {
+	return func(map[string]any) (any, error) { return v, nil }
+}
+
+// value parses a constant: literal, parameter, record ID, array, object or
+// a supported function call.
+func (p *parser) value() (any, error) {
+	e, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return e(nil)
+}
+
+// condition parses a boolean expression: comparisons joined with AND/OR.
+func (p *parser) condition() (expr, error) {
+	left, err := p.andExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") || p.punct("||") {
+		right, err := p.andExpr()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row map[string]any) (any, error) {
+			a, err := l(row)
+			if err != nil || truthy(a) {
+				return truthy(a), err
+			}
+			b, err := right(row)
+			return truthy(b), err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) andExpr() (expr, error) {
+	left, err := p.notExpr()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") || p.punct("&&") {
+		right, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row map[string]any) (any, error) {
+			a, err := l(row)
+			if err != nil || !truthy(a) {
+				return false, err
+			}
+			b, err := right(row)
+			return truthy(b), err
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) notExpr() (expr, error) {
+	if p.punct("!") || p.keyword("NOT") {
+		inner, err := p.notExpr()
+		if err != nil {
+			return nil, err
+		}
+		return func(row map[string]any) (any, error) {
+			v, err := inner(row)
+			return !truthy(v), err
+		}, nil
+	}
+	return p.comparison()
+}
+
+func (p *parser) comparison() (expr, error) {
+	left, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	op := ""
+	switch t := p.peek(); {
+	case t.kind == tokPunct && comparisonOps[t.text]:
+		op = p.next().text
+	case p.keyword("IS", "NOT"):
+		op = "!="
+	case p.keyword("IS"):
+		op = "="
+	case p.keyword("NOT", "IN"):
+		op = "NOT IN"
+	case p.keyword("IN"), p.keyword("INSIDE"):
+		op = "IN"
+	case p.keyword("CONTAINS"):
+		op = "CONTAINS"
+	default:
+		return left, nil
+	}
+	right, err := p.operand()
+	if err != nil {
+		return nil, err
+	}
+	return func(row map[string]any) (any, error) {
+		a, err := left(row)
+		if err != nil {
+			return nil, err
+		}
+		b, err := right(row)
+		if err != nil {
+			return nil, err
+		}
+		return compare(op, a, b), nil
+	}, nil
+}
+
+var comparisonOps = map[string]bool{"=": true, "==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+func (p *parser) operand() (expr, error) {
+	if p.done() {
+		return nil, p.errorf("unexpected end of statement")
+	}
+	t := p.next()
+	switch t.kind {
+	case tokParam:
+		v, ok := p.vars[t.text]
+		if !ok {
+			return constant(nil), nil
+		}
+		return constant(normalize(v)), nil
+	case tokNumber:
+		if strings.Contains(t.text, ".") {
+			f, err := strconv.ParseFloat(t.text, 64)
+			return constant(f), err
+		}
+		n, err := strconv.ParseInt(t.text, 10, 64)
+		return constant(n), err
+	case tokString:
+		return constant(t.text), nil
+	case tokPunct:
+		switch t.text {
+		case "(":
+			inner, err := p.condition()
+			if err != nil {
+				return nil, err
+			}
+			return inner, p.expectPunct(")")
+		case "-":
+			v, err := p.operand()
+			if err != nil {
+				return nil, err
+			}
+			return func(row map[string]any) (any, error) {
+				n, err := v(row)
+				switch n := n.(type) {
+				case int64:
+					return -n, err
+				case float64:
+					return -n, err
+				}
+				return nil, fmt.Errorf("cannot negate %v", n)
+			}, nil
+		case "[":
+			return p.array()
+		case "{":
+			return p.object()
+		}
+	case tokIdent:
+		if !t.quoted {
+			switch strings.ToUpper(t.text) {
+			case "TRUE":
+				return constant(true), nil
+			case "FALSE":
+				return constant(false), nil
+			case "NONE", "NULL":
+				return constant(nil), nil
+			}
+		}
+		if p.peek().is(tokPunct, "(") {
+			return p.call(t.text)
+		}
+		if p.peek().is(tokPunct, ":") {
+			p.next()
+			key, err := p.recordKey()
+			if err != nil {
+				return nil, err
+			}
+			return constant(models.NewRecordID(t.text, key)), nil
+		}
+		path := []string{t.text}
+		for p.peek().is(tokPunct, ".") {
+			p.next()
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, name)
+		}
+		return func(row map[string]any) (any, error) {
+			return lookup(row, path), nil
+		}, nil
+	}
+	p.i--
+	return nil, p.errorf("unexpected %q", t.text)
+}
+
+func (p *parser) array() (expr, error) {
+	var items []expr
+	for !p.punct("]") {
+		if len(items) > 0 {
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+			if p.punct("]") {
+				break
+			}
+		}
+		item, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return func(row map[string]any) (any, error) {
+		out := make([]any, len(items))
+		for i, item := range items {
+			v, err := item(row)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = v
+		}
+		return out, nil
+	}, nil
+}
+
+func (p *parser) object() (expr, error) {
+	keys := []string{}
+	values := []expr{}
+	for !p.punct("}") {
+		if len(keys) > 0 {
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+			if p.punct("}") {
+				break
+			}
+		}
+		t := p.next()
+		if t.kind != tokIdent && t.kind != tokString && t.kind != tokNumber {
+			return nil, p.errorf("expected object key")
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		v, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, t.text)
+		values = append(values, v)
+	}
+	return func(row map[string]any) (any, error) {
+		out := make(map[string]any, len(keys))
+		for i, k := range keys {
+			v, err := values[i](row)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = v
+		}
+		return out, nil
+	}, nil
+}
+
+// call supports the functions the ORM renders itself.
+func (p *parser) call(name string) (expr, error) {
+	p.next()
+	var args []expr
+	for !p.punct(")") {
+		if len(args) > 0 {
+			if err := p.expectPunct(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	switch strings.ToLower(name) {
+	case "time::now":
+		return func(map[string]any) (any, error) { return time.Now().UTC(), nil }, nil
+	}
+	return nil, fmt.Errorf("ormtest: unsupported function %s()", name)
+}
+
+// recordKey parses the key after "table:".
+func (p *parser) recordKey() (any, error) {
+	if p.done() {
+		return nil, p.errorf("missing record key")
+	}
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, nil
+		}
+	case tokIdent, tokString:
+		return t.text, nil
+	case tokPunct:
+		switch t.text {
+		case "[":
+			e, err := p.array()
+			if err != nil {
+				return nil, err
+			}
+			return e(nil)
+		case "{":
+			e, err := p.object()
+			if err != nil {
+				return nil, err
+			}
+			return e(nil)
+		}
+	}
+	p.i--
+	return nil, p.errorf("invalid record key")
+}
+
+func lookup(row map[string]any, path []string) any {
+	var cur any = row
+	for _, name := range path {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return nil
+		}
+		cur = m[name]
+	}
+	return cur
+}
+
+func truthy(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case int64:
+		return t != 0
+	case float64:
+		return t != 0
+	case string:
+		return t != ""
+	}
+	return true
+}
+
+// normalize converts Go values bound as parameters into the shapes stored
+// by the fake: int64, float64, []any and map[string]any.
+func normalize(v any) any {
+	switch t := v.(type) {
+	case nil, bool, string, int64, float64, time.Time, models.RecordID:
+		return t
+	case *models.RecordID:
+		if t == nil {
+			return nil
+		}
+		return *t
+	case interface{ RecordID() models.RecordID }:
+		return t.RecordID()
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, item := range t {
+			out[k] = normalize(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = normalize(item)
+		}
+		return out
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return normalize(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			out[i] = normalize(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out[iter.Key().String()] = normalize(iter.Value().Interface())
+		}
+		return out
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return v
+}
+
+func compare(op string, a, b any) bool {
+	switch op {
+	case "=", "==":
+		return equal(a, b)
+	case "!=":
+		return !equal(a, b)
+	case "IN", "NOT IN":
+		items, _ := b.([]any)
+		found := false
+		for _, item := range items {
+			if equal(a, item) {
+				found = true
+				break
+			}
+		}
+		return found == (op == "IN")
+	case "CONTAINS":
+		return compare("IN", b, a)
+	}
+	c, ok := order(a, b)
+	if !ok {
+		return false
+	}
+	switch op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func equal(a, b any) bool {
+	if c, ok := order(a, b); ok {
+		return c == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// order compares two scalar values of the same kind.
+func order(a, b any) (int, bool) {
+	switch x := a.(type) {
+	case nil:
+		if b == nil {
+			return 0, true
+		}
+	case int64, float64:
+		y, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		xf, _ := toFloat(x)
+		return cmpFloat(xf, y), true
+	case string:
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmpFloat(boolFloat(x), boolFloat(y)), true
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y), true
+		}
+	case models.RecordID:
+		if y, ok := b.(models.RecordID); ok {
+			if c := strings.Compare(x.Table, y.Table); c != 0 {
+				return c, true
+			}
+			if c, ok := order(normalize(x.ID), normalize(y.ID)); ok {
+				return c, true
+			}
+			return strings.Compare(fmt.Sprint(x.ID), fmt.Sprint(y.ID)), true
+		}
+	}
+	return 0, false
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sortRows orders rows by the given fields; desc flags descending order.
+func sortRows(rows []map[string]any, fields [][]string, desc []bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		for k, path := range fields {
+			c, ok := order(lookup(rows[i], path), lookup(rows[j], path))
+			if !ok || c == 0 {
+				continue
+			}
+			if desc[k] {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
@@ -0,0 +1,249 @@
+package ormtest
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+)
+
+// update makes AssertGolden rewrite golden files instead of comparing them.
+// The flag is namespaced so it does not clash with a test binary's own
+// -update flag.
+var update = flag.Bool("ormtest.update", false, "rewrite ormtest golden files")
+
+// Recorded is one query captured by a Recorder.
+type Recorded struct {
+	SQL  string
+	Vars map[string]any
+}
+
+// Recorder wraps a DB and records every query sent through it, so tests can
+// compare the SurrealQL a service produces against a golden file. It
+// implements migrator.DB, orm.DB and orm.BatchDB; wrap it in an
+// orm.Executor to record repository calls.
+type Recorder struct {
+	db      orm.DB
+	mu      sync.Mutex
+	entries []Recorded
+}
+
+// NewRecorder records queries and forwards them to db, which may be nil to
+// record without executing; queries then return no rows, and batches one
+// empty result per statement.
+func NewRecorder(db orm.DB) *Recorder {
+	return &Recorder{db: db}
+}
+
+func (r *Recorder) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
+	r.record(sql, vars)
+	if r.db == nil {
+		return nil, nil
+	}
+	return r.db.Query(ctx, sql, vars)
+}
+
+func (r *Recorder) QueryBatch(ctx context.Context, sql string, vars map[string]any) ([]orm.StatementResult, error) {
+	if r.db == nil {
+		r.record(sql, vars)
+		return emptyResults(sql)
+	}
+	db, ok := r.db.(orm.BatchDB)
+	if !ok {
+		return nil, orm.ErrBatchUnsupported
+	}
+	r.record(sql, vars)
+	return db.QueryBatch(ctx, sql, vars)
+}
+
+// emptyResults returns an empty OK result for each statement in sql except
+// transaction control, matching what DB.QueryBatch reports.
+func emptyResults(sql string) ([]orm.StatementResult, error) {
+	toks, err := lex(sql)
+	if err != nil {
+		return nil, err
+	}
+	var results []orm.StatementResult
+	for _, stmt := range splitStatements(toks) {
+		switch {
+		case stmt[0].isKeyword("BEGIN"), stmt[0].isKeyword("COMMIT"), stmt[0].isKeyword("CANCEL"):
+			continue
+		}
+		results = append(results, orm.StatementResult{Status: "OK"})
+	}
+	return results, nil
+}
+
+func (r *Recorder) record(sql string, vars map[string]any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var copied map[string]any
+	if vars != nil {
+		copied = make(map[string]any, len(vars))
+		for k, v := range vars {
+			copied[k] = v
+		}
+	}
+	r.entries = append(r.entries, Recorded{SQL: sql, Vars: copied})
+}
+
+// Recorded returns the queries captured so far, in order.
+func (r *Recorder) Recorded() []Recorded {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Recorded(nil), r.entries...)
+}
+
+// Reset discards the captured queries.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = nil
+}
+
+// String renders the captured queries in golden file form. Generated
+// parameters ($p1, $p2, ...) are renumbered in order of appearance within
+// each query and their values listed after it, so a change in parameter
+// numbering alone does not alter the output.
+func (r *Recorder) String() string {
+	var b strings.Builder
+	for i, e := range r.Recorded() {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "-- query %d\n", i+1)
+		sql, args := normalizeQuery(e.SQL, e.Vars)
+		b.WriteString(strings.TrimSpace(sql))
+		b.WriteByte('\n')
+		for _, arg := range args {
+			fmt.Fprintf(&b, "-- $%s = %s\n", arg.name, literal(normalize(arg.value)))
+		}
+	}
+	return b.String()
+}
+
+// AssertGolden compares the captured queries with testdata/<name>.golden
+// and fails t on any difference. Run the tests with -ormtest.update to
+// rewrite the file instead.
+func (r *Recorder) AssertGolden(t testing.TB, name string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	got := r.String()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("ormtest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("ormtest: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ormtest: %v (run with -ormtest.update to create it)", err)
+	}
+	if string(want) != got {
+		t.Errorf("ormtest: queries differ from %s (run with -ormtest.update to accept):\n%s", path, diffLines(string(want), got))
+	}
+}
+
+type namedArg struct {
+	name  string
+	value any
+}
+
+var generatedParam = regexp.MustCompile(`^p[0-9]+$`)
+
+// normalizeQuery renames the generated parameters of sql in order of first
+// appearance and returns the rewritten text with its arguments: renamed
+// ones first, then any others by name.
+func normalizeQuery(sql string, vars map[string]any) (string, []namedArg) {
+	toks, err := lex(sql)
+	if err != nil {
+		toks = nil
+	}
+	renamed := map[string]string{}
+	var (
+		b    strings.Builder
+		args []namedArg
+		last int
+	)
+	for _, tok := range toks {
+		if tok.kind != tokParam || !generatedParam.MatchString(tok.text) {
+			continue
+		}
+		value, ok := vars[tok.text]
+		if !ok {
+			continue
+		}
+		name, seen := renamed[tok.text]
+		if !seen {
+			name = fmt.Sprintf("p%d", len(renamed)+1)
+			renamed[tok.text] = name
+			args = append(args, namedArg{name: name, value: value})
+		}
+		b.WriteString(sql[last:tok.pos])
+		b.WriteString("$" + name)
+		last = tok.end
+	}
+	b.WriteString(sql[last:])
+
+	var rest []string
+	for k := range vars {
+		if _, ok := renamed[k]; !ok {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	for _, k := range rest {
+		args = append(args, namedArg{name: k, value: vars[k]})
+	}
+	return b.String(), args
+}
+
+// diffLines returns a line diff of want and got, prefixing removed lines
+// with "-" and added ones with "+".
+func diffLines(want, got string) string {
+	a := strings.Split(want, "\n")
+	b := strings.Split(got, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + b[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + a[i] + "\n")
+			i++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,90 @@
+package ormtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
+	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
+)
+
+func TestRecorderGolden(t *testing.T) {
+	ctx := context.Background()
+	rec := NewRecorder(New())
+	exec := &orm.Executor{DB: rec}
+
+	if _, err := exec.Exec(ctx, qb.Create(qb.T("note")).Content(map[string]any{"title": "a", "rank": 2})); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	rows, err := exec.Exec(ctx, qb.Select().From(qb.T("note")).Where(qb.I("title").Eq("a")))
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected the created note, got %v %v", rows, err)
+	}
+	if _, err := exec.ExecBatch(ctx, qb.Delete(qb.T("note")).Where(qb.I("rank").Eq(2)), qb.Select().From(qb.T("note"))); err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	rec.AssertGolden(t, "recorder")
+}
+
+func TestNormalizeQueryRenumbersParams(t *testing.T) {
+	sql, args := normalizeQuery("SELECT * FROM a WHERE x = $p7 AND y = $p3 AND z = $p7 AND s = '$p3' AND w = $name",
+		map[string]any{"p7": 1, "p3": 2, "name": "n"})
+	if want := "SELECT * FROM a WHERE x = $p1 AND y = $p2 AND z = $p1 AND s = '$p3' AND w = $name"; sql != want {
+		t.Fatalf("unexpected sql:\n%s", sql)
+	}
+	want := []namedArg{{"p1", 1}, {"p2", 2}, {"name", "n"}}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected args: %v", args)
+	}
+
+	a := NewRecorder(nil)
+	b := NewRecorder(nil)
+	_, _ = a.Query(context.Background(), "SELECT * FROM a WHERE x = $p1", map[string]any{"p1": 1})
+	_, _ = b.Query(context.Background(), "SELECT * FROM a WHERE x = $p4", map[string]any{"p4": 1})
+	if a.String() != b.String() {
+		t.Fatalf("expected numbering to be normalised:\n%s\n%s", a, b)
+	}
+}
+
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertGoldenReportsDiff(t *testing.T) {
+	if *update {
+		t.Skip("golden files are being rewritten")
+	}
+	rec := NewRecorder(nil)
+	_, _ = rec.Query(context.Background(), "SELECT * FROM other", nil)
+	tb := &recordingTB{TB: t}
+	rec.AssertGolden(tb, "recorder")
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "+ SELECT * FROM other") || !strings.Contains(tb.errors[0], "-ormtest.update") {
+		t.Fatalf("expected a diff, got %v", tb.errors)
+	}
+}
+
+func TestRecorderWithoutDBCommitsUnitOfWork(t *testing.T) {
+	rec := NewRecorder(nil)
+	uow := orm.NewUnitOfWork(&orm.Executor{DB: rec})
+	first := uow.Add(qb.Create(qb.T("note")).Content(map[string]any{"title": "a"}))
+	second := uow.Add(qb.Delete(qb.T("note")))
+	if err := uow.Commit(context.Background()); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if first.Err != nil || second.Err != nil {
+		t.Fatalf("unexpected op errors: %v %v", first.Err, second.Err)
+	}
+	if got := rec.Recorded(); len(got) != 1 || !strings.HasPrefix(got[0].SQL, "BEGIN TRANSACTION") {
+		t.Fatalf("expected the transaction to be recorded, got %v", got)
+	}
+}
@@ -0,0 +1,220 @@
+package ormtest
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokIdent tokenKind = iota
+	tokNumber
+	tokString
+	tokParam
+	tokPunct
+)
+
+// token is a lexed SurrealQL token. text holds the identifier (unescaped for
+// quoted identifiers), the unquoted string, the parameter name without "$"
+// or the punctuation symbol.
+type token struct {
+	kind   tokenKind
+	text   string
+	quoted bool
+	pos    int
+	end    int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+// isKeyword reports whether t is the unquoted identifier kw, ignoring case.
+func (t token) isKeyword(kw string) bool {
+	return t.kind == tokIdent && !t.quoted && strings.EqualFold(t.text, kw)
+}
+
+var multiPunct = []string{"!=", "==", "<=", ">=", "+=", "-=", "&&", "||", "->", "<-"}
+
+func lex(src string) ([]token, error) {
+	var toks []token
+	i := 0
+	for i < len(src) {
+		r, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+		case strings.HasPrefix(src[i:], "--"), strings.HasPrefix(src[i:], "//"), r == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("ormtest: unterminated comment")
+			}
+			i += end + 4
+		case r == '\'' || r == '"':
+			s, end, err := lexString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tokString, text: s, pos: i, end: end})
+			i = end
+		case r == '`':
+			end := strings.IndexByte(src[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("ormtest: unterminated identifier")
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i+1 : i+1+end], quoted: true, pos: i, end: i + end + 2})
+			i += end + 2
+		case r == '⟨':
+			text, end, err := lexAngle(src, i+size)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, token{kind: tokIdent, text: text, quoted: true, pos: i, end: end})
+			i = end
+		case r == '$':
+			j := i + 1
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			toks = append(toks, token{kind: tokParam, text: src[i+1 : j], pos: i, end: j})
+			i = j
+		case r < utf8.RuneSelf && isWordByte(byte(r)):
+			j := i
+			for j < len(src) && (isWordByte(src[j]) || strings.HasPrefix(src[j:], "::")) {
+				if src[j] == ':' {
+					j += 2
+					continue
+				}
+				j++
+			}
+			word := src[i:j]
+			// Prefixed strings such as d"2024-01-01" or r"user:1".
+			if len(word) == 1 && j < len(src) && (src[j] == '\'' || src[j] == '"') && strings.ContainsAny(word, "drsu") {
+				s, end, err := lexString(src, j)
+				if err != nil {
+					return nil, err
+				}
+				toks = append(toks, token{kind: tokString, text: s, pos: i, end: end})
+				i = end
+				continue
+			}
+			if isNumber(word) {
+				// Decimal part.
+				if j+1 < len(src) && src[j] == '.' && src[j+1] >= '0' && src[j+1] <= '9' {
+					j++
+					for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+						j++
+					}
+				}
+				toks = append(toks, token{kind: tokNumber, text: src[i:j], pos: i, end: j})
+			} else {
+				toks = append(toks, token{kind: tokIdent, text: word, pos: i, end: j})
+			}
+			i = j
+		default:
+			text := string(r)
+			for _, p := range multiPunct {
+				if strings.HasPrefix(src[i:], p) {
+					text = p
+					break
+				}
+			}
+			toks = append(toks, token{kind: tokPunct, text: text, pos: i, end: i + len(text)})
+			i += len(text)
+		}
+	}
+	return toks, nil
+}
+
+func lexString(src string, start int) (string, int, error) {
+	quote := src[start]
+	var b strings.Builder
+	for i := start + 1; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(src[i])
+			}
+		case c == quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, fmt.Errorf("ormtest: unterminated string")
+}
+
+// lexAngle reads a ⟨...⟩ identifier body starting at start, where a
+// backslash escapes the next character.
+func lexAngle(src string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start; i < len(src); {
+		if src[i] == '\\' && i+1 < len(src) {
+			_, size := utf8.DecodeRuneInString(src[i+1:])
+			b.WriteString(src[i+1 : i+1+size])
+			i += 1 + size
+			continue
+		}
+		if strings.HasPrefix(src[i:], "⟩") {
+			return b.String(), i + len("⟩"), nil
+		}
+		b.WriteByte(src[i])
+		i++
+	}
+	return "", 0, fmt.Errorf("ormtest: unterminated identifier")
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+func isNumber(word string) bool {
+	for i := 0; i < len(word); i++ {
+		if word[i] < '0' || word[i] > '9' {
+			return false
+		}
+	}
+	return word != ""
+}
+
+// splitStatements splits tokens on top-level semicolons.
+func splitStatements(toks []token) [][]token {
+	var out [][]token
+	depth, start := 0, 0
+	for i, t := range toks {
+		if t.kind != tokPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		case ";":
+			if depth == 0 {
+				if i > start {
+					out = append(out, toks[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	if start < len(toks) {
+		out = append(out, toks[start:])
+	}
+	return out
+}
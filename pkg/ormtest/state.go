@@ -0,0 +1,221 @@
+package ormtest
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// state is the schema and data of the fake database.
+type state struct {
+	tables map[string]*table
+	// resources holds database-level definitions by INFO FOR DB key,
+	// e.g. "accesses" or "functions".
+	resources map[string]map[string]string
+}
+
+type table struct {
+	def     string
+	fields  map[string]string
+	indexes map[string]string
+	events  map[string]string
+	// unique maps unique index names to their field paths.
+	unique  map[string][][]string
+	records map[string]map[string]any
+	order   []string
+}
+
+func newState() *state {
+	return &state{tables: map[string]*table{}, resources: map[string]map[string]string{}}
+}
+
+func newTable(def string) *table {
+	return &table{
+		def:     def,
+		fields:  map[string]string{},
+		indexes: map[string]string{},
+		events:  map[string]string{},
+		unique:  map[string][][]string{},
+		records: map[string]map[string]any{},
+	}
+}
+
+// clone copies the state for transaction rollback. Records are copied one
+// level deep, which is enough since statements replace field values rather
+// than mutate them.
+func (s *state) clone() *state {
+	out := newState()
+	for name, t := range s.tables {
+		c := newTable(t.def)
+		maps.Copy(c.fields, t.fields)
+		maps.Copy(c.indexes, t.indexes)
+		maps.Copy(c.events, t.events)
+		maps.Copy(c.unique, t.unique)
+		for key, rec := range t.records {
+			c.records[key] = copyRecord(rec)
+		}
+		c.order = append([]string(nil), t.order...)
+		out.tables[name] = c
+	}
+	for kind, defs := range s.resources {
+		out.resources[kind] = maps.Clone(defs)
+	}
+	return out
+}
+
+// implicitTable returns table name, defining it the way SurrealDB does when
+// a statement touches an undefined table.
+func (s *state) implicitTable(name string) *table {
+	t := s.tables[name]
+	if t == nil {
+		t = newTable(fmt.Sprintf("DEFINE TABLE %s TYPE ANY SCHEMALESS PERMISSIONS NONE", name))
+		s.tables[name] = t
+	}
+	return t
+}
+
+func (t *table) insert(id models.RecordID, rec map[string]any) error {
+	key := recordKey(id.ID)
+	if _, ok := t.records[key]; ok {
+		return fmt.Errorf("Database record `%s` already exists", recordString(id))
+	}
+	if err := t.checkUnique(key, rec); err != nil {
+		return err
+	}
+	t.records[key] = rec
+	t.order = append(t.order, key)
+	return nil
+}
+
+func (t *table) replace(key string, rec map[string]any) error {
+	if err := t.checkUnique(key, rec); err != nil {
+		return err
+	}
+	t.records[key] = rec
+	return nil
+}
+
+func (t *table) remove(key string) {
+	delete(t.records, key)
+	for i, k := range t.order {
+		if k == key {
+			t.order = append(t.order[:i], t.order[i+1:]...)
+			break
+		}
+	}
+}
+
+// checkUnique enforces UNIQUE indexes for rec stored under key.
+func (t *table) checkUnique(key string, rec map[string]any) error {
+	for name, paths := range t.unique {
+		values := make([]any, len(paths))
+		missing := true
+		for i, path := range paths {
+			values[i] = lookup(rec, path)
+			missing = missing && values[i] == nil
+		}
+		if missing {
+			continue
+		}
+		for _, other := range t.order {
+			if other == key {
+				continue
+			}
+			existing := t.records[other]
+			same := true
+			for i, path := range paths {
+				if !equal(lookup(existing, path), values[i]) {
+					same = false
+					break
+				}
+			}
+			if same {
+				shown := any(values)
+				if len(values) == 1 {
+					shown = values[0]
+				}
+				return fmt.Errorf("Database index `%s` already contains %s, with record `%s`", name, literal(shown), recordString(existing["id"].(models.RecordID)))
+			}
+		}
+	}
+	return nil
+}
+
+// rows returns the records selected by target: the whole table, or one
+// record when key is set.
+func (t *table) rows(key *string) []map[string]any {
+	if t == nil {
+		return nil
+	}
+	if key != nil {
+		if rec, ok := t.records[*key]; ok {
+			return []map[string]any{rec}
+		}
+		return nil
+	}
+	out := make([]map[string]any, 0, len(t.order))
+	for _, k := range t.order {
+		out = append(out, t.records[k])
+	}
+	return out
+}
+
+func copyRecord(rec map[string]any) map[string]any {
+	return maps.Clone(rec)
+}
+
+// recordKey identifies a record key within its table.
+func recordKey(key any) string {
+	key = normalize(key)
+	return fmt.Sprintf("%T:%s", key, literal(key))
+}
+
+func recordString(id models.RecordID) string {
+	return id.Table + ":" + keyLiteral(normalize(id.ID))
+}
+
+func keyLiteral(key any) string {
+	if s, ok := key.(string); ok {
+		if isNumber(s) || s == "" || strings.IndexFunc(s, func(r rune) bool { return r > 127 || !isWordByte(byte(r)) }) >= 0 {
+			return "⟨" + strings.NewReplacer(`\`, `\\`, "⟩", `\⟩`).Replace(s) + "⟩"
+		}
+		return s
+	}
+	return literal(key)
+}
+
+// literal renders a value as SurrealQL for error messages and keys.
+func literal(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "NONE"
+	case string:
+		return "'" + strings.ReplaceAll(t, "'", `\'`) + "'"
+	case models.RecordID:
+		return recordString(t)
+	case time.Time:
+		return "d'" + t.UTC().Format(time.RFC3339Nano) + "'"
+	case []any:
+		parts := make([]string, len(t))
+		for i, item := range t {
+			parts[i] = literal(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + ": " + literal(t[k])
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,790 @@
+package ormtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/surrealdb/surrealdb.go/pkg/models"
+)
+
+// exec runs one statement against the current state.
+func (db *DB) exec(p *parser) ([]map[string]any, error) {
+	switch {
+	case p.keyword("DEFINE"):
+		return nil, db.define(p)
+	case p.keyword("REMOVE"):
+		return nil, db.remove(p)
+	case p.keyword("INFO", "FOR"):
+		return db.info(p)
+	case p.keyword("CREATE"):
+		return db.create(p)
+	case p.keyword("SELECT"):
+		return db.selectRows(p)
+	case p.keyword("UPDATE"):
+		return db.update(p, false)
+	case p.keyword("UPSERT"):
+		return db.update(p, true)
+	case p.keyword("DELETE"):
+		return db.delete(p)
+	}
+	return nil, p.errorf("unsupported statement")
+}
+
+// dbResources maps DEFINE kinds stored at database level to their INFO FOR
+// DB key.
+var dbResources = map[string]string{
+	"ACCESS":   "accesses",
+	"ANALYZER": "analyzers",
+	"FUNCTION": "functions",
+	"PARAM":    "params",
+	"USER":     "users",
+}
+
+// define stores the statement text, minus OVERWRITE / IF NOT EXISTS, as the
+// resource definition reported by INFO. Redefining a resource replaces it
+// unless IF NOT EXISTS is given.
+func (db *DB) define(p *parser) error {
+	kind := strings.ToUpper(p.next().text)
+	p.keyword("OVERWRITE")
+	ifNotExists := p.keyword("IF", "NOT", "EXISTS")
+	if p.done() {
+		return p.errorf("missing %s name", kind)
+	}
+	def := "DEFINE " + kind + " " + p.rest()
+	switch kind {
+	case "TABLE":
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if t := db.state.tables[name]; t != nil {
+			if !ifNotExists {
+				t.def = def
+			}
+			return nil
+		}
+		db.state.tables[name] = newTable(def)
+		return nil
+	case "FIELD", "INDEX", "EVENT":
+		name, tableName, err := p.tableResource()
+		if err != nil {
+			return err
+		}
+		t := db.state.implicitTable(tableName)
+		defs := t.resource(kind)
+		if _, ok := defs[name]; ok && ifNotExists {
+			return nil
+		}
+		defs[name] = def
+		if kind == "INDEX" {
+			return t.defineIndex(name, p)
+		}
+		return nil
+	}
+	key, ok := dbResources[kind]
+	if !ok {
+		return p.errorf("unsupported DEFINE %s", kind)
+	}
+	name := p.next().text
+	if db.state.resources[key] == nil {
+		db.state.resources[key] = map[string]string{}
+	}
+	if _, ok := db.state.resources[key][name]; ok && ifNotExists {
+		return nil
+	}
+	db.state.resources[key][name] = def
+	return nil
+}
+
+func (db *DB) remove(p *parser) error {
+	kind := strings.ToUpper(p.next().text)
+	ifExists := p.keyword("IF", "EXISTS")
+	missing := func(name string) error {
+		if ifExists {
+			return nil
+		}
+		return fmt.Errorf("The %s '%s' does not exist", strings.ToLower(kind), name)
+	}
+	switch kind {
+	case "TABLE":
+		name, err := p.ident()
+		if err != nil {
+			return err
+		}
+		if db.state.tables[name] == nil {
+			return missing(name)
+		}
+		delete(db.state.tables, name)
+		return nil
+	case "FIELD", "INDEX", "EVENT":
+		name, tableName, err := p.tableResource()
+		if err != nil {
+			return err
+		}
+		t := db.state.tables[tableName]
+		if t == nil {
+			return missing(name)
+		}
+		defs := t.resource(kind)
+		if _, ok := defs[name]; !ok {
+			return missing(name)
+		}
+		delete(defs, name)
+		delete(t.unique, name)
+		return nil
+	}
+	key, ok := dbResources[kind]
+	if !ok {
+		return p.errorf("unsupported REMOVE %s", kind)
+	}
+	name := p.next().text
+	if _, ok := db.state.resources[key][name]; !ok {
+		return missing(name)
+	}
+	delete(db.state.resources[key], name)
+	return nil
+}
+
+// tableResource parses "<name> ON [TABLE] <table>" and returns the name as
+// written, which may be a nested path such as address.city.
+func (p *parser) tableResource() (string, string, error) {
+	start := p.i
+	for !p.done() && !p.peek().isKeyword("ON") {
+		p.next()
+	}
+	if p.i == start || p.done() {
+		return "", "", p.errorf("expected <name> ON <table>")
+	}
+	name := p.src[p.toks[start].pos:p.toks[p.i-1].end]
+	if p.i-start == 1 {
+		name = p.toks[start].text
+	}
+	p.next()
+	p.keyword("TABLE")
+	tableName, err := p.ident()
+	return name, tableName, err
+}
+
+func (t *table) resource(kind string) map[string]string {
+	switch kind {
+	case "FIELD":
+		return t.fields
+	case "INDEX":
+		return t.indexes
+	}
+	return t.events
+}
+
+// defineIndex records the field paths of UNIQUE indexes.
+func (t *table) defineIndex(name string, p *parser) error {
+	delete(t.unique, name)
+	var paths [][]string
+	unique := false
+	for !p.done() {
+		switch {
+		case p.keyword("FIELDS"), p.keyword("COLUMNS"):
+			for {
+				path, err := p.fieldPath()
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+				if !p.punct(",") {
+					break
+				}
+			}
+		case p.keyword("UNIQUE"):
+			unique = true
+		default:
+			p.next()
+		}
+	}
+	if unique && len(paths) > 0 {
+		t.unique[name] = paths
+	}
+	return nil
+}
+
+func (p *parser) fieldPath() ([]string, error) {
+	name, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+	path := []string{name}
+	for p.punct(".") {
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, name)
+	}
+	return path, nil
+}
+
+func (db *DB) info(p *parser) ([]map[string]any, error) {
+	switch {
+	case p.keyword("DB"), p.keyword("DATABASE"):
+		row := map[string]any{"tables": map[string]any{}}
+		for _, key := range dbResources {
+			row[key] = definitions(db.state.resources[key])
+		}
+		tables := row["tables"].(map[string]any)
+		for name, t := range db.state.tables {
+			tables[name] = t.def
+		}
+		return []map[string]any{row}, nil
+	case p.keyword("TABLE"):
+		name, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+		t := db.state.tables[name]
+		if t == nil {
+			return nil, fmt.Errorf("The table '%s' does not exist", name)
+		}
+		return []map[string]any{{
+			"events":  definitions(t.events),
+			"fields":  definitions(t.fields),
+			"indexes": definitions(t.indexes),
+			"tables":  map[string]any{},
+		}}, nil
+	}
+	return nil, p.errorf("unsupported INFO statement")
+}
+
+func definitions(defs map[string]string) map[string]any {
+	out := make(map[string]any, len(defs))
+	for name, def := range defs {
+		out[name] = def
+	}
+	return out
+}
+
+// target is the subject of a data statement: a table or one record.
+type target struct {
+	table string
+	id    *models.RecordID
+}
+
+func (t target) key() *string {
+	if t.id == nil {
+		return nil
+	}
+	key := recordKey(t.id.ID)
+	return &key
+}
+
+func (p *parser) target() (target, error) {
+	p.keyword("ONLY")
+	if t := p.peek(); t.kind == tokParam {
+		p.next()
+		switch v := normalize(p.vars[t.text]).(type) {
+		case models.RecordID:
+			return target{table: v.Table, id: &v}, nil
+		case string:
+			return target{table: v}, nil
+		}
+		return target{}, fmt.Errorf("ormtest: cannot use $%s as a target", t.text)
+	}
+	name, err := p.ident()
+	if err != nil {
+		return target{}, err
+	}
+	if !p.punct(":") {
+		return target{table: name}, nil
+	}
+	key, err := p.recordKey()
+	if err != nil {
+		return target{}, err
+	}
+	id := models.NewRecordID(name, key)
+	return target{table: name, id: &id}, nil
+}
+
+// assignment is one "field op value" entry of a SET clause.
+type assignment struct {
+	path  []string
+	op    string
+	value expr
+}
+
+func (p *parser) assignments() ([]assignment, error) {
+	var out []assignment
+	for {
+		path, err := p.fieldPath()
+		if err != nil {
+			return nil, err
+		}
+		op := p.next()
+		if op.kind != tokPunct || (op.text != "=" && op.text != "+=" && op.text != "-=") {
+			return nil, p.errorf("expected assignment operator")
+		}
+		value, err := p.operand()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, assignment{path: path, op: op.text, value: value})
+		if !p.punct(",") {
+			return out, nil
+		}
+	}
+}
+
+func applyAssignments(rec map[string]any, assigns []assignment) error {
+	for _, a := range assigns {
+		v, err := a.value(rec)
+		if err != nil {
+			return err
+		}
+		current := lookup(rec, a.path)
+		switch a.op {
+		case "+=":
+			v, err = add(current, v)
+		case "-=":
+			v, err = subtract(current, v)
+		}
+		if err != nil {
+			return fmt.Errorf("ormtest: %s %s: %w", strings.Join(a.path, "."), a.op, err)
+		}
+		setPath(rec, a.path, v)
+	}
+	return nil
+}
+
+func add(a, b any) (any, error) {
+	switch x := a.(type) {
+	case nil:
+		return b, nil
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x + y, nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return x + y, nil
+		}
+	case []any:
+		if y, ok := b.([]any); ok {
+			return append(append([]any(nil), x...), y...), nil
+		}
+		return append(append([]any(nil), x...), b), nil
+	}
+	xf, ok1 := toFloat(a)
+	yf, ok2 := toFloat(b)
+	if ok1 && ok2 {
+		return xf + yf, nil
+	}
+	return nil, fmt.Errorf("cannot add %v and %v", a, b)
+}
+
+func subtract(a, b any) (any, error) {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x - y, nil
+		}
+	case []any:
+		out := []any{}
+		for _, item := range x {
+			if !equal(item, b) {
+				out = append(out, item)
+			}
+		}
+		return out, nil
+	}
+	xf, ok1 := toFloat(a)
+	yf, ok2 := toFloat(b)
+	if ok1 && ok2 {
+		return xf - yf, nil
+	}
+	return nil, fmt.Errorf("cannot subtract %v from %v", b, a)
+}
+
+// setPath sets a possibly nested field; NONE removes it.
+func setPath(rec map[string]any, path []string, v any) {
+	for _, name := range path[:len(path)-1] {
+		next, ok := rec[name].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+		} else {
+			next = copyRecord(next)
+		}
+		rec[name] = next
+		rec = next
+	}
+	last := path[len(path)-1]
+	if v == nil {
+		delete(rec, last)
+		return
+	}
+	rec[last] = v
+}
+
+// returnClause parses RETURN NONE | BEFORE | AFTER | <fields>, returning
+// fallback when the clause is missing.
+func (p *parser) returnClause(fallback string) (string, []projection, error) {
+	if !p.keyword("RETURN") {
+		return fallback, nil, nil
+	}
+	for _, mode := range []string{"NONE", "BEFORE", "AFTER"} {
+		if p.keyword(mode) {
+			return mode, nil, nil
+		}
+	}
+	fields, err := p.projections()
+	return "FIELDS", fields, err
+}
+
+func (p *parser) trailing() error {
+	p.keyword("PARALLEL")
+	if !p.done() {
+		return p.errorf("unsupported clause")
+	}
+	return nil
+}
+
+func (db *DB) create(p *parser) ([]map[string]any, error) {
+	tgt, err := p.target()
+	if err != nil {
+		return nil, err
+	}
+	rec := map[string]any{}
+	switch {
+	case p.keyword("CONTENT"):
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		content, ok := v.(map[string]any)
+		if !ok && v != nil {
+			return nil, fmt.Errorf("ormtest: CREATE CONTENT expects an object, got %T", v)
+		}
+		rec = copyRecord(content)
+		if rec == nil {
+			rec = map[string]any{}
+		}
+	case p.keyword("SET"):
+		assigns, err := p.assignments()
+		if err != nil {
+			return nil, err
+		}
+		if err := applyAssignments(rec, assigns); err != nil {
+			return nil, err
+		}
+	}
+	mode, fields, err := p.returnClause("AFTER")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.trailing(); err != nil {
+		return nil, err
+	}
+	id, err := db.newID(tgt, rec["id"])
+	if err != nil {
+		return nil, err
+	}
+	rec["id"] = id
+	if err := db.state.implicitTable(tgt.table).insert(id, rec); err != nil {
+		return nil, err
+	}
+	return returned(mode, fields, nil, rec), nil
+}
+
+// newID picks the record ID for CREATE: the target record, the content id,
+// or a generated key.
+func (db *DB) newID(tgt target, contentID any) (models.RecordID, error) {
+	if tgt.id != nil {
+		return *tgt.id, nil
+	}
+	switch v := contentID.(type) {
+	case nil:
+		const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+		key := make([]byte, 20)
+		for i := range key {
+			key[i] = alphabet[db.rng.IntN(len(alphabet))]
+		}
+		return models.NewRecordID(tgt.table, string(key)), nil
+	case models.RecordID:
+		if v.Table != tgt.table {
+			return models.RecordID{}, fmt.Errorf("Found %s for the id field, but a specific record has been specified", recordString(v))
+		}
+		return v, nil
+	}
+	return models.NewRecordID(tgt.table, contentID), nil
+}
+
+func (db *DB) selectRows(p *parser) ([]map[string]any, error) {
+	fields, err := p.projections()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	tgt, err := p.target()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.filter(db.state.tables[tgt.table].rows(tgt.key()))
+	if err != nil {
+		return nil, err
+	}
+	if p.keyword("ORDER") {
+		p.keyword("BY")
+		var paths [][]string
+		var desc []bool
+		for {
+			path, err := p.fieldPath()
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, path)
+			desc = append(desc, p.keyword("DESC"))
+			p.keyword("ASC")
+			if !p.punct(",") {
+				break
+			}
+		}
+		sortRows(rows, paths, desc)
+	}
+	if p.keyword("LIMIT") {
+		p.keyword("BY")
+		n, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		if p.keyword("START") {
+			p.keyword("AT")
+			start, err := p.count()
+			if err != nil {
+				return nil, err
+			}
+			rows = rows[min(start, len(rows)):]
+		}
+		rows = rows[:min(n, len(rows))]
+	} else if p.keyword("START") {
+		p.keyword("AT")
+		start, err := p.count()
+		if err != nil {
+			return nil, err
+		}
+		rows = rows[min(start, len(rows)):]
+	}
+	if err := p.trailing(); err != nil {
+		return nil, err
+	}
+	out := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		out[i] = project(fields, row)
+	}
+	return out, nil
+}
+
+// filter applies an optional WHERE clause.
+func (p *parser) filter(rows []map[string]any) ([]map[string]any, error) {
+	if !p.keyword("WHERE") {
+		return rows, nil
+	}
+	cond, err := p.condition()
+	if err != nil {
+		return nil, err
+	}
+	out := []map[string]any{}
+	for _, row := range rows {
+		v, err := cond(row)
+		if err != nil {
+			return nil, err
+		}
+		if truthy(v) {
+			out = append(out, row)
+		}
+	}
+	return out, nil
+}
+
+func (p *parser) count() (int, error) {
+	v, err := p.value()
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(int64)
+	if !ok || n < 0 {
+		return 0, fmt.Errorf("ormtest: expected a non-negative integer, got %v", v)
+	}
+	return int(n), nil
+}
+
+// projection is one selected field; a nil path selects the whole record.
+type projection struct {
+	path  []string
+	alias []string
+}
+
+func (p *parser) projections() ([]projection, error) {
+	var out []projection
+	for {
+		if p.punct("*") {
+			out = append(out, projection{})
+		} else {
+			path, err := p.fieldPath()
+			if err != nil {
+				return nil, err
+			}
+			alias := path
+			if p.keyword("AS") {
+				if alias, err = p.fieldPath(); err != nil {
+					return nil, err
+				}
+			}
+			out = append(out, projection{path: path, alias: alias})
+		}
+		if !p.punct(",") {
+			return out, nil
+		}
+	}
+}
+
+func project(fields []projection, row map[string]any) map[string]any {
+	out := map[string]any{}
+	for _, f := range fields {
+		if f.path == nil {
+			for k, v := range row {
+				out[k] = v
+			}
+			continue
+		}
+		if v := lookup(row, f.path); v != nil {
+			setPath(out, f.alias, v)
+		}
+	}
+	return out
+}
+
+func returned(mode string, fields []projection, before, after map[string]any) []map[string]any {
+	switch mode {
+	case "NONE":
+		return nil
+	case "BEFORE":
+		if before == nil {
+			return nil
+		}
+		return []map[string]any{copyRecord(before)}
+	case "FIELDS":
+		return []map[string]any{project(fields, after)}
+	}
+	if after == nil {
+		return nil
+	}
+	return []map[string]any{copyRecord(after)}
+}
+
+func (db *DB) update(p *parser, upsert bool) ([]map[string]any, error) {
+	tgt, err := p.target()
+	if err != nil {
+		return nil, err
+	}
+	var change func(rec map[string]any) (map[string]any, error)
+	switch {
+	case p.keyword("MERGE"):
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		patch, _ := v.(map[string]any)
+		change = func(rec map[string]any) (map[string]any, error) {
+			for k, item := range patch {
+				if k != "id" {
+					setPath(rec, []string{k}, item)
+				}
+			}
+			return rec, nil
+		}
+	case p.keyword("CONTENT"):
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		content, _ := v.(map[string]any)
+		change = func(rec map[string]any) (map[string]any, error) {
+			out := copyRecord(content)
+			if out == nil {
+				out = map[string]any{}
+			}
+			out["id"] = rec["id"]
+			return out, nil
+		}
+	case p.keyword("SET"):
+		assigns, err := p.assignments()
+		if err != nil {
+			return nil, err
+		}
+		change = func(rec map[string]any) (map[string]any, error) {
+			return rec, applyAssignments(rec, assigns)
+		}
+	default:
+		change = func(rec map[string]any) (map[string]any, error) { return rec, nil }
+	}
+	t := db.state.tables[tgt.table]
+	rows := t.rows(tgt.key())
+	if upsert && len(rows) == 0 && tgt.id != nil {
+		t = db.state.implicitTable(tgt.table)
+		rec := map[string]any{"id": *tgt.id}
+		if err := t.insert(*tgt.id, rec); err != nil {
+			return nil, err
+		}
+		rows = []map[string]any{rec}
+	}
+	rows, err = p.filter(rows)
+	if err != nil {
+		return nil, err
+	}
+	mode, fields, err := p.returnClause("AFTER")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.trailing(); err != nil {
+		return nil, err
+	}
+	var out []map[string]any
+	for _, before := range rows {
+		after, err := change(copyRecord(before))
+		if err != nil {
+			return nil, err
+		}
+		if err := t.replace(recordKey(before["id"].(models.RecordID).ID), after); err != nil {
+			return nil, err
+		}
+		out = append(out, returned(mode, fields, before, after)...)
+	}
+	return out, nil
+}
+
+func (db *DB) delete(p *parser) ([]map[string]any, error) {
+	p.keyword("FROM")
+	tgt, err := p.target()
+	if err != nil {
+		return nil, err
+	}
+	t := db.state.tables[tgt.table]
+	rows, err := p.filter(t.rows(tgt.key()))
+	if err != nil {
+		return nil, err
+	}
+	mode, fields, err := p.returnClause("NONE")
+	if err != nil {
+		return nil, err
+	}
+	if err := p.trailing(); err != nil {
+		return nil, err
+	}
+	var out []map[string]any
+	for _, rec := range rows {
+		t.remove(recordKey(rec["id"].(models.RecordID).ID))
+		if mode == "FIELDS" {
+			out = append(out, project(fields, rec))
+		} else if mode != "NONE" {
+			out = append(out, copyRecord(rec))
+		}
+	}
+	return out, nil
+}
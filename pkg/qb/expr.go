@@ -16,9 +16,23 @@ func (e Expr[T]) build(b *Builder) {
 	e.node.build(b)
 }
 
+func (e Expr[T]) unwrap() Node {
+	return e.node
+}
+
 // Condition is a boolean expression.
 type Condition = Expr[bool]
 
+// IsZero reports whether the expression holds no node, e.g. an unset WHERE.
+func (e Expr[T]) IsZero() bool {
+	return e.node == nil
+}
+
+// Node returns the underlying AST node.
+func (e Expr[T]) Node() Node {
+	return e.node
+}
+
 // RawExpr injects raw SurrealQL.
 type RawExpr struct {
 	Text string
@@ -46,6 +60,12 @@ func V(val any) Expr[any] {
 	return Expr[any]{node: Value{Val: val}}
 }
 
+// VOf binds val as an expression of its own type, for helpers that take
+// typed arguments.
+func VOf[T any](val T) Expr[T] {
+	return Expr[T]{node: Value{Val: val}}
+}
+
 func (v Value) build(b *Builder) {
 	b.Write(b.Arg(v.Val))
 }
@@ -102,6 +122,24 @@ func (a Alias) build(b *Builder) {
 	b.Write(a.Alias)
 }
 
+// Graph is a graph traversal starting at From, e.g. "$p1->likes->post".
+// Path holds the arrows and tables that follow the start.
+type Graph struct {
+	From Node
+	Path string
+}
+
+// GraphFrom traverses path from a record; a record ID value is bound as a
+// parameter: GraphFrom(id, "->likes->post").
+func GraphFrom(from any, path string) Expr[any] {
+	return Expr[any]{node: Graph{From: ensureValueNode(from), Path: path}}
+}
+
+func (g Graph) build(b *Builder) {
+	writeOperand(b, g.From, nodePrecedence(g.From) != precAtom)
+	b.Write(g.Path)
+}
+
 // Binary is a binary operator node.
 type Binary struct {
 	Left  Node
@@ -110,13 +148,11 @@ type Binary struct {
 }
 
 func (bop Binary) build(b *Builder) {
-	b.Write("(")
-	bop.Left.build(b)
+	writeOperand(b, bop.Left, needsParens(bop.Op, bop.Left, false))
 	b.Write(" ")
 	b.Write(bop.Op)
 	b.Write(" ")
-	bop.Right.build(b)
-	b.Write(")")
+	writeOperand(b, bop.Right, needsParens(bop.Op, bop.Right, true))
 }
 
 // Unary is a unary operator node.
@@ -127,7 +163,8 @@ type Unary struct {
 
 func (u Unary) build(b *Builder) {
 	b.Write(u.Op)
-	u.Expr.build(b)
+	// A nested prefix operator is wrapped too: "--x" would start a comment.
+	writeOperand(b, u.Expr, nodePrecedence(u.Expr) <= precPrefix)
 }
 
 // FuncCall represents "fn(arg1, arg2, ...)".
@@ -140,6 +177,11 @@ func Fn(name string, args ...Node) Expr[any] {
 	return Expr[any]{node: FuncCall{Name: name, Args: args}}
 }
 
+// FnOf builds a function call whose result type is known.
+func FnOf[T any](name string, args ...Node) Expr[T] {
+	return Expr[T]{node: FuncCall{Name: name, Args: args}}
+}
+
 func (f FuncCall) build(b *Builder) {
 	b.Write(f.Name)
 	b.Write("(")
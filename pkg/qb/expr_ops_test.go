@@ -14,10 +14,12 @@ func TestExprBasics(t *testing.T) {
 	}
 
 	assertQuery(t, Return(As(I("x"), "alias")), "RETURN x AS alias")
-	assertQuery(t, Return(Binary{Left: I("a"), Op: "+", Right: I("b")}), "RETURN (a + b)")
+	assertQuery(t, Return(Binary{Left: I("a"), Op: "+", Right: I("b")}), "RETURN a + b")
 	assertQuery(t, Return(Unary{Op: "!", Expr: I("flag")}), "RETURN !flag")
 	assertQuery(t, Return(Fn("fn", I("a"), V(2))), "RETURN fn(a, $p1)")
 	assertQuery(t, Return(L(I("a"), I("b"))), "RETURN [a, b]")
+	assertQuery(t, Select().From(GraphFrom(PWith("from", 1), "->likes->post")), "SELECT * FROM $from->likes->post")
+	assertQuery(t, Select().From(GraphFrom(Raw("a, b"), "<-likes<-user")), "SELECT * FROM (a, b)<-likes<-user")
 
 	if got := trimParamName("$x"); got != "x" {
 		t.Fatalf("expected trim of $x, got %s", got)
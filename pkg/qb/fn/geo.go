@@ -0,0 +1,41 @@
+package qbfn
+
+import "github.com/yaroher/surrealdb.go.orm/pkg/qb"
+
+// Geometry is implemented by geometry values, such as orm.GeoPoint, that
+// bind as SurrealDB geometries.
+type Geometry interface {
+	GeometryKind() string
+}
+
+// Point is satisfied by point geometries such as orm.GeoPoint.
+type Point interface {
+	~struct {
+		Longitude float64
+		Latitude  float64
+	}
+	Geometry
+}
+
+// GeoDistance builds geo::distance(from, to), the distance in metres between
+// two points. Pass point fields as qb.F[orm.GeoPoint]("loc").Expr() and
+// point values as qb.VOf(orm.NewGeoPoint(lon, lat)).
+func GeoDistance[P Point](from, to qb.Expr[P]) qb.Expr[float64] {
+	return qb.FnOf[float64]("geo::distance", from, to)
+}
+
+// GeoBearing builds geo::bearing(from, to), the initial bearing in degrees
+// between two points.
+func GeoBearing[P Point](from, to qb.Expr[P]) qb.Expr[float64] {
+	return qb.FnOf[float64]("geo::bearing", from, to)
+}
+
+// GeoArea builds geo::area(geometry), the area in square metres.
+func GeoArea[G Geometry](geometry qb.Expr[G]) qb.Expr[float64] {
+	return qb.FnOf[float64]("geo::area", geometry)
+}
+
+// GeoCentroid builds geo::centroid(geometry).
+func GeoCentroid[G Geometry](geometry qb.Expr[G]) qb.Expr[any] {
+	return qb.FnOf[any]("geo::centroid", geometry)
+}
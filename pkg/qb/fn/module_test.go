@@ -3,6 +3,9 @@ package qbfn
 import (
 	"testing"
 
+	"github.com/fxamacker/cbor/v2"
+	"github.com/surrealdb/surrealdb.go/surrealcbor"
+	"github.com/yaroher/surrealdb.go.orm/pkg/orm"
 	"github.com/yaroher/surrealdb.go.orm/pkg/qb"
 )
 
@@ -43,3 +46,51 @@ func TestModuleCall(t *testing.T) {
 		t.Fatalf("unexpected rand: %s", q.Text)
 	}
 }
+
+func TestGeoHelpers(t *testing.T) {
+	from := qb.F[orm.GeoPoint]("location").Expr()
+	origin := orm.NewGeoPoint(-0.1276, 51.5072)
+	q := qb.Build(qb.Return(GeoDistance(from, qb.VOf(origin))))
+	if q.Text != "RETURN geo::distance(location, $p1)" {
+		t.Fatalf("unexpected geo distance: %s", q.Text)
+	}
+	if q.Args["p1"] != origin {
+		t.Fatalf("expected the point to be bound as is, got %#v", q.Args["p1"])
+	}
+
+	var bearing qb.Expr[float64] = GeoBearing(from, qb.VOf(origin))
+	q = qb.Build(qb.Return(bearing))
+	if q.Text != "RETURN geo::bearing(location, $p1)" {
+		t.Fatalf("unexpected geo bearing: %s", q.Text)
+	}
+
+	area := qb.F[orm.GeoPolygon]("area").Expr()
+	q = qb.Build(qb.Return(GeoArea(area)))
+	if q.Text != "RETURN geo::area(area)" {
+		t.Fatalf("unexpected geo area: %s", q.Text)
+	}
+
+	q = qb.Build(qb.Return(GeoCentroid(area)))
+	if q.Text != "RETURN geo::centroid(area)" {
+		t.Fatalf("unexpected geo centroid: %s", q.Text)
+	}
+}
+
+func TestGeoPointBindsAsGeometry(t *testing.T) {
+	q := qb.Build(qb.Return(GeoDistance(qb.F[orm.GeoPoint]("location").Expr(), qb.VOf(orm.NewGeoPoint(1.5, 2)))))
+	data, err := surrealcbor.Marshal(q.Args)
+	if err != nil {
+		t.Fatalf("marshal args: %v", err)
+	}
+	var args map[string]cbor.RawTag
+	if err := cbor.Unmarshal(data, &args); err != nil {
+		t.Fatalf("unmarshal args: %v", err)
+	}
+	if args["p1"].Number != 88 {
+		t.Fatalf("expected a geometry point tag (88), got %d", args["p1"].Number)
+	}
+	var coords [2]float64
+	if err := cbor.Unmarshal(args["p1"].Content, &coords); err != nil || coords != [2]float64{1.5, 2} {
+		t.Fatalf("unexpected point content %v: %v", coords, err)
+	}
+}
@@ -0,0 +1,606 @@
+package qb
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupportedNode is returned when a node has no JSON form. Schema
+// statements (DEFINE, REMOVE, ...) are not serialisable; DML, expressions
+// and control flow are.
+var ErrUnsupportedNode = errors.New("qb: node has no JSON form")
+
+// ErrUnsupportedValue is returned when a bound value would not decode back
+// to the same type: structs (record ids, times, geometries, ...), byte
+// slices, channels and the like. Bind such values as parameters at
+// execution time instead of storing them in the node.
+var ErrUnsupportedValue = errors.New("qb: value has no lossless JSON form")
+
+// ErrInvalidJSON is returned when a JSON node is missing a child or field
+// its kind requires.
+var ErrInvalidJSON = errors.New("qb: invalid JSON node")
+
+// nodeJSON is the stable JSON shape of a node. Kind selects which of the
+// other fields are meaningful; literal values are stored under "data".
+type nodeJSON struct {
+	Kind     string         `json:"kind"`
+	Name     string         `json:"name,omitempty"`
+	Text     string         `json:"text,omitempty"`
+	Op       string         `json:"op,omitempty"`
+	Data     any            `json:"data,omitempty"`
+	Bound    bool           `json:"bound,omitempty"`
+	Args     map[string]any `json:"args,omitempty"`
+	Alias    string         `json:"alias,omitempty"`
+	Expr     *nodeJSON      `json:"expr,omitempty"`
+	Left     *nodeJSON      `json:"left,omitempty"`
+	Right    *nodeJSON      `json:"right,omitempty"`
+	Items    []*nodeJSON    `json:"items,omitempty"`
+	Target   *nodeJSON      `json:"target,omitempty"`
+	From     []*nodeJSON    `json:"from,omitempty"`
+	Edge     *nodeJSON      `json:"edge,omitempty"`
+	To       *nodeJSON      `json:"to,omitempty"`
+	Mode     string         `json:"mode,omitempty"`
+	Content  *nodeJSON      `json:"content,omitempty"`
+	Set      []*nodeJSON    `json:"set,omitempty"`
+	Where    *nodeJSON      `json:"where,omitempty"`
+	OrderBy  []*nodeJSON    `json:"order_by,omitempty"`
+	GroupBy  []*nodeJSON    `json:"group_by,omitempty"`
+	Split    []*nodeJSON    `json:"split,omitempty"`
+	Limit    *nodeJSON      `json:"limit,omitempty"`
+	Start    *nodeJSON      `json:"start,omitempty"`
+	Fetch    []*nodeJSON    `json:"fetch,omitempty"`
+	Timeout  *nodeJSON      `json:"timeout,omitempty"`
+	Parallel bool           `json:"parallel,omitempty"`
+	Return   *nodeJSON      `json:"return,omitempty"`
+	Option   string         `json:"option,omitempty"`
+	Dir      string         `json:"dir,omitempty"`
+	Params   []string       `json:"params,omitempty"`
+	Body     *nodeJSON      `json:"body,omitempty"`
+	Branches []branchJSON   `json:"branches,omitempty"`
+	Else     *nodeJSON      `json:"else,omitempty"`
+}
+
+type branchJSON struct {
+	Cond *nodeJSON `json:"cond"`
+	Body *nodeJSON `json:"body,omitempty"`
+}
+
+// MarshalNode encodes a statement or expression in its stable JSON form.
+// Bound values must be JSON-native; anything else fails with
+// ErrUnsupportedValue rather than decoding back as a different type.
+func MarshalNode(n Node) ([]byte, error) {
+	j, err := toJSON(n)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalNode decodes a node produced by MarshalNode. Fields decode as
+// Field[any], integers as int64 (uint64 beyond its range) and floats as
+// float64. A node missing a child
+// its kind requires fails with ErrInvalidJSON.
+func UnmarshalNode(data []byte) (Node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var j nodeJSON
+	if err := dec.Decode(&j); err != nil {
+		return nil, err
+	}
+	return fromJSON(&j)
+}
+
+// UnmarshalStatement decodes a statement produced by MarshalNode.
+func UnmarshalStatement(data []byte) (Statement, error) {
+	n, err := UnmarshalNode(data)
+	if err != nil {
+		return nil, err
+	}
+	if n == nil {
+		return nil, errors.New("qb: empty statement")
+	}
+	return n, nil
+}
+
+// JSONStatement adapts a statement to encoding/json, e.g. as a field of a
+// stored "saved query" record.
+type JSONStatement struct {
+	Statement Statement
+}
+
+func (j JSONStatement) MarshalJSON() ([]byte, error) {
+	if j.Statement == nil {
+		return []byte("null"), nil
+	}
+	return MarshalNode(j.Statement)
+}
+
+func (j *JSONStatement) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		j.Statement = nil
+		return nil
+	}
+	stmt, err := UnmarshalStatement(data)
+	if err != nil {
+		return err
+	}
+	j.Statement = stmt
+	return nil
+}
+
+func toJSON(n Node) (*nodeJSON, error) {
+	n = unwrapNode(n)
+	if n == nil {
+		return nil, nil
+	}
+	switch t := n.(type) {
+	case RawExpr:
+		return &nodeJSON{Kind: "raw", Text: t.Text}, nil
+	case Value:
+		data, err := jsonValue(t.Val)
+		if err != nil {
+			return nil, err
+		}
+		return &nodeJSON{Kind: "value", Data: data}, nil
+	case Param:
+		data, err := jsonValue(t.Value)
+		if err != nil {
+			return nil, fmt.Errorf("param $%s: %w", t.Name, err)
+		}
+		return &nodeJSON{Kind: "param", Name: t.Name, Data: data, Bound: t.Has}, nil
+	case Ident:
+		return &nodeJSON{Kind: "ident", Name: t.Name}, nil
+	case Table:
+		return &nodeJSON{Kind: "table", Name: t.Name()}, nil
+	case FieldRef:
+		return &nodeJSON{Kind: "field", Name: t.FieldName()}, nil
+	case allProjection:
+		return &nodeJSON{Kind: "all"}, nil
+	case rawKeywordStatement:
+		return &nodeJSON{Kind: "keyword", Text: string(t)}, nil
+	case RawStatement:
+		var args map[string]any
+		for name, arg := range t.Args {
+			data, err := jsonValue(arg)
+			if err != nil {
+				return nil, fmt.Errorf("param $%s: %w", name, err)
+			}
+			if args == nil {
+				args = make(map[string]any, len(t.Args))
+			}
+			args[name] = data
+		}
+		return &nodeJSON{Kind: "raw_statement", Text: t.Text, Args: args}, nil
+	case BreakStatement:
+		return &nodeJSON{Kind: "break"}, nil
+	case ContinueStatement:
+		return &nodeJSON{Kind: "continue"}, nil
+	}
+
+	kids := children(n)
+	enc, err := toJSONList(kids)
+	if err != nil {
+		return nil, err
+	}
+	r := &jsonReader{nodes: enc}
+	switch t := n.(type) {
+	case Alias:
+		return &nodeJSON{Kind: "alias", Expr: r.next(), Alias: t.Alias}, nil
+	case Graph:
+		return &nodeJSON{Kind: "graph", Expr: r.next(), Text: t.Path}, nil
+	case Binary:
+		return &nodeJSON{Kind: "binary", Op: t.Op, Left: r.next(), Right: r.next()}, nil
+	case Unary:
+		return &nodeJSON{Kind: "unary", Op: t.Op, Expr: r.next()}, nil
+	case FuncCall:
+		return &nodeJSON{Kind: "call", Name: t.Name, Items: r.rest()}, nil
+	case List:
+		return &nodeJSON{Kind: "list", Items: r.rest()}, nil
+	case Subquery:
+		return &nodeJSON{Kind: "subquery", Expr: r.next()}, nil
+	case StmtExpr:
+		return &nodeJSON{Kind: "statement_expr", Expr: r.next()}, nil
+	case Block:
+		return &nodeJSON{Kind: "block", Body: r.next()}, nil
+	case Chain:
+		return &nodeJSON{Kind: "chain", Items: r.rest()}, nil
+	case Order:
+		return &nodeJSON{Kind: "order", Expr: r.next(), Option: t.option, Dir: t.dir}, nil
+	case Assignment:
+		return &nodeJSON{Kind: "assign", Op: t.Op, Left: r.next(), Right: r.next()}, nil
+	case *SelectBuilder:
+		return &nodeJSON{
+			Kind:     "select",
+			Items:    r.take(len(t.projections)),
+			From:     r.take(len(t.from)),
+			Where:    r.next(),
+			OrderBy:  r.take(len(t.orders)),
+			GroupBy:  r.take(len(t.groupBy)),
+			Split:    r.take(len(t.splitOn)),
+			Limit:    r.next(),
+			Start:    r.next(),
+			Fetch:    r.take(len(t.fetch)),
+			Timeout:  r.next(),
+			Parallel: t.parallel,
+		}, nil
+	case *CreateBuilder:
+		return &nodeJSON{Kind: "create", Target: r.next(), Content: r.next(), Set: r.take(len(t.set)), Return: r.next()}, nil
+	case *InsertBuilder:
+		return &nodeJSON{Kind: "insert", Target: r.next(), Items: r.take(len(t.values)), Return: r.next()}, nil
+	case *UpdateBuilder:
+		return &nodeJSON{Kind: "update", Target: r.next(), Mode: t.dataMode, Content: r.next(), Set: r.take(len(t.set)), Where: r.next(), Return: r.next()}, nil
+	case *DeleteBuilder:
+		return &nodeJSON{Kind: "delete", Target: r.next(), Where: r.next(), Return: r.next()}, nil
+	case *RelateBuilder:
+		return &nodeJSON{Kind: "relate", Target: r.next(), Edge: r.next(), To: r.next(), Set: r.take(len(t.set)), Return: r.next()}, nil
+	case *ReturnStatement:
+		return &nodeJSON{Kind: "return", Expr: r.next()}, nil
+	case *LetStatement:
+		return &nodeJSON{Kind: "let", Name: t.Name, Expr: r.next()}, nil
+	case *ThrowStatement:
+		return &nodeJSON{Kind: "throw", Expr: r.next()}, nil
+	case *IfBuilder:
+		out := &nodeJSON{Kind: "if"}
+		for range t.branches {
+			out.Branches = append(out.Branches, branchJSON{Cond: r.next(), Body: r.next()})
+		}
+		out.Else = r.next()
+		return out, nil
+	case *ForBuilder:
+		return &nodeJSON{Kind: "for", Params: t.params, Expr: r.next(), Body: r.next()}, nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedNode, n)
+}
+
+func toJSONList(nodes []Node) ([]*nodeJSON, error) {
+	out := make([]*nodeJSON, len(nodes))
+	for i, n := range nodes {
+		j, err := toJSON(n)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = j
+	}
+	return out, nil
+}
+
+type jsonReader struct {
+	nodes []*nodeJSON
+}
+
+func (r *jsonReader) next() *nodeJSON {
+	if len(r.nodes) == 0 {
+		return nil
+	}
+	n := r.nodes[0]
+	r.nodes = r.nodes[1:]
+	return n
+}
+
+func (r *jsonReader) take(count int) []*nodeJSON {
+	out := make([]*nodeJSON, 0, count)
+	for i := 0; i < count; i++ {
+		out = append(out, r.next())
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func (r *jsonReader) rest() []*nodeJSON {
+	return r.take(len(r.nodes))
+}
+
+func fromJSON(j *nodeJSON) (Node, error) {
+	if j == nil {
+		return nil, nil
+	}
+	if j.Name == "" && (j.Kind == "param" || j.Kind == "ident" || j.Kind == "table" || j.Kind == "field") {
+		return nil, fmt.Errorf("%w: %s node without name", ErrInvalidJSON, j.Kind)
+	}
+	switch j.Kind {
+	case "raw":
+		return RawExpr{Text: j.Text}, nil
+	case "value":
+		return Value{Val: normalizeJSON(j.Data)}, nil
+	case "param":
+		return Param{Name: j.Name, Value: normalizeJSON(j.Data), Has: j.Bound}, nil
+	case "ident":
+		return Ident{Name: j.Name}, nil
+	case "table":
+		return Table(j.Name), nil
+	case "field":
+		return F[any](j.Name), nil
+	case "all":
+		return All, nil
+	case "keyword":
+		return rawKeywordStatement(j.Text), nil
+	case "raw_statement":
+		args, _ := normalizeJSON(j.Args).(map[string]any)
+		return RawStatement{Text: j.Text, Args: args}, nil
+	case "break":
+		return BreakStatement{}, nil
+	case "continue":
+		return ContinueStatement{}, nil
+	}
+
+	d := &jsonDecoder{kind: j.Kind}
+	var out Node
+	switch j.Kind {
+	case "alias":
+		out = Alias{Expr: d.required("expr", j.Expr), Alias: d.text("alias", j.Alias)}
+	case "graph":
+		out = Graph{From: d.required("expr", j.Expr), Path: d.text("text", j.Text)}
+	case "binary":
+		out = Binary{Left: d.required("left", j.Left), Op: d.text("op", j.Op), Right: d.required("right", j.Right)}
+	case "unary":
+		out = Unary{Op: d.text("op", j.Op), Expr: d.required("expr", j.Expr)}
+	case "call":
+		out = FuncCall{Name: d.text("name", j.Name), Args: d.nodes(j.Items)}
+	case "list":
+		out = List{Items: d.nodes(j.Items)}
+	case "subquery":
+		out = Subquery{Stmt: d.required("expr", j.Expr)}
+	case "statement_expr":
+		out = StmtExpr{Stmt: d.required("expr", j.Expr)}
+	case "block":
+		out = Block{Body: d.required("body", j.Body)}
+	case "chain":
+		stmts := make([]Statement, 0, len(j.Items))
+		for _, n := range d.nodes(j.Items) {
+			stmts = append(stmts, n)
+		}
+		out = Chain{Statements: stmts}
+	case "order":
+		out = Order{field: d.required("expr", j.Expr), option: j.Option, dir: j.Dir}
+	case "assign":
+		out = Assignment{Field: d.required("left", j.Left), Op: d.text("op", j.Op), Value: d.required("right", j.Right)}
+	case "select":
+		out = &SelectBuilder{
+			projections: d.nodes(j.Items),
+			from:        d.nodes(j.From),
+			where:       Condition{node: d.node(j.Where)},
+			orders:      d.orders(j.OrderBy),
+			groupBy:     d.nodes(j.GroupBy),
+			splitOn:     d.nodes(j.Split),
+			limit:       d.node(j.Limit),
+			start:       d.node(j.Start),
+			fetch:       d.nodes(j.Fetch),
+			timeout:     d.node(j.Timeout),
+			parallel:    j.Parallel,
+		}
+	case "create":
+		out = &CreateBuilder{target: d.required("target", j.Target), content: d.node(j.Content), set: d.assignments(j.Set), returning: d.node(j.Return)}
+	case "insert":
+		out = &InsertBuilder{into: d.required("target", j.Target), values: d.nodes(j.Items), returning: d.node(j.Return)}
+	case "update":
+		out = &UpdateBuilder{
+			target:    d.required("target", j.Target),
+			dataMode:  j.Mode,
+			data:      d.node(j.Content),
+			set:       d.assignments(j.Set),
+			where:     Condition{node: d.node(j.Where)},
+			returning: d.node(j.Return),
+		}
+	case "delete":
+		out = &DeleteBuilder{target: d.required("target", j.Target), where: Condition{node: d.node(j.Where)}, returning: d.node(j.Return)}
+	case "relate":
+		out = &RelateBuilder{from: d.required("target", j.Target), edge: d.required("edge", j.Edge), to: d.required("to", j.To), set: d.assignments(j.Set), returning: d.node(j.Return)}
+	case "return":
+		out = &ReturnStatement{Value: d.required("expr", j.Expr)}
+	case "let":
+		out = &LetStatement{Name: d.text("name", j.Name), Value: d.required("expr", j.Expr)}
+	case "throw":
+		out = &ThrowStatement{Value: d.required("expr", j.Expr)}
+	case "if":
+		if len(j.Branches) == 0 {
+			d.fail("branches")
+		}
+		stmt := &IfBuilder{elseBody: d.node(j.Else)}
+		for _, br := range j.Branches {
+			stmt.branches = append(stmt.branches, ifBranch{Cond: Condition{node: d.required("cond", br.Cond)}, Body: d.required("body", br.Body)})
+		}
+		out = stmt
+	case "for":
+		if len(j.Params) == 0 {
+			d.fail("params")
+		}
+		out = &ForBuilder{params: j.Params, flowType: "IN", iterable: d.required("expr", j.Expr), body: d.required("body", j.Body)}
+	default:
+		return nil, fmt.Errorf("qb: unknown node kind %q", j.Kind)
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+	return out, nil
+}
+
+// jsonDecoder decodes the children of a node of the given kind, keeping the
+// first error.
+type jsonDecoder struct {
+	kind string
+	err  error
+}
+
+func (d *jsonDecoder) fail(field string) {
+	if d.err == nil {
+		d.err = fmt.Errorf("%w: %s node without %s", ErrInvalidJSON, d.kind, field)
+	}
+}
+
+// required decodes a child that must be present.
+func (d *jsonDecoder) required(field string, j *nodeJSON) Node {
+	if j == nil {
+		d.fail(field)
+		return nil
+	}
+	return d.node(j)
+}
+
+// text returns a string field that must be non-empty.
+func (d *jsonDecoder) text(field, v string) string {
+	if v == "" {
+		d.fail(field)
+	}
+	return v
+}
+
+func (d *jsonDecoder) node(j *nodeJSON) Node {
+	if d.err != nil || j == nil {
+		return nil
+	}
+	n, err := fromJSON(j)
+	if err != nil {
+		d.err = err
+	}
+	return n
+}
+
+func (d *jsonDecoder) nodes(items []*nodeJSON) []Node {
+	if len(items) == 0 {
+		return nil
+	}
+	out := make([]Node, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			d.fail("null items")
+			return nil
+		}
+		if n := d.node(item); n != nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (d *jsonDecoder) orders(items []*nodeJSON) []Order {
+	var out []Order
+	for _, n := range d.nodes(items) {
+		out = append(out, asOrder(n, Order{}))
+	}
+	return out
+}
+
+func (d *jsonDecoder) assignments(items []*nodeJSON) []Assignment {
+	var out []Assignment
+	for _, n := range d.nodes(items) {
+		a, ok := n.(Assignment)
+		if !ok {
+			d.fail("an assignment in set")
+			return nil
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// jsonValue returns v in a form that encodes losslessly, or
+// ErrUnsupportedValue if v holds anything but nil, booleans, strings, finite
+// numbers and slices or string-keyed maps of those. Whole floats are written
+// with a trailing ".0" so they decode as float64 again.
+func jsonValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return jsonReflect(reflect.ValueOf(v))
+}
+
+func jsonReflect(rv reflect.Value) (any, error) {
+	switch rv.Kind() {
+	case reflect.Invalid:
+		return nil, nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			break
+		}
+		text := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eE") {
+			text += ".0"
+		}
+		return json.Number(text), nil
+	case reflect.Pointer, reflect.Interface:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return jsonReflect(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			item, err := jsonReflect(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			out[i] = item
+		}
+		return out, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			item, err := jsonReflect(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			out[iter.Key().String()] = item
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedValue, rv.Type())
+}
+
+// normalizeJSON turns json.Number values into int64, uint64 (above the
+// int64 range) or float64.
+func normalizeJSON(v any) any {
+	switch t := v.(type) {
+	case json.Number:
+		if !strings.ContainsAny(string(t), ".eE") {
+			if i, err := t.Int64(); err == nil {
+				return i
+			}
+			if u, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+				return u
+			}
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]any:
+		for k, item := range t {
+			t[k] = normalizeJSON(item)
+		}
+		return t
+	case []any:
+		for i, item := range t {
+			t[i] = normalizeJSON(item)
+		}
+		return t
+	}
+	return v
+}
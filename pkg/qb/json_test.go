@@ -0,0 +1,162 @@
+package qb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func roundTrip(t *testing.T, stmt Statement) Statement {
+	t.Helper()
+	data, err := MarshalNode(stmt)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	out, err := UnmarshalStatement(data)
+	if err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, data)
+	}
+	want, got := Build(stmt), Build(out)
+	if got.Text != want.Text {
+		t.Fatalf("round trip changed query:\n%s\nexpected:\n%s", got.Text, want.Text)
+	}
+	// Numbers come back as int64/float64, so compare printed values.
+	if fmt.Sprint(got.Args) != fmt.Sprint(want.Args) {
+		t.Fatalf("round trip changed args: %#v, expected %#v", got.Args, want.Args)
+	}
+	return out
+}
+
+func TestJSONRoundTripSelect(t *testing.T) {
+	stmt := Select(F[string]("name"), As(Fn("count"), "n")).
+		From(T("user")).
+		Where(And(F[int]("age").Gt(18), Or(I("role").In("admin", "owner"), F[bool]("active").Eq(true)))).
+		OrderBy(OrderBy(F[string]("created_at")).Desc()).
+		Limit(10).
+		Start(20).
+		Fetch(I("friends")).
+		Parallel()
+	roundTrip(t, stmt)
+}
+
+func TestJSONRoundTripModifications(t *testing.T) {
+	roundTrip(t, Select().From(GraphFrom("user:1", "->likes->post")))
+	roundTrip(t, Create(T("user")).Content(map[string]any{"name": "ann", "tags": []any{"a", "b"}}))
+	roundTrip(t, Create(T("user")).Set(Set(I("name"), "bob")).Return(Raw("AFTER")))
+	roundTrip(t, Insert(T("user")).Values(map[string]any{"name": "a"}, map[string]any{"name": "b"}))
+	roundTrip(t, Update(I("user:1")).Merge(map[string]any{"age": int64(3)}).Where(F[int]("age").Lt(2.5)))
+	roundTrip(t, Delete(T("user")).Where(F[string]("name").Eq("x")))
+	roundTrip(t, Relate(I("user:1"), T("likes"), I("post:2")).Set(Set(I("at"), Fn("time::now"))))
+}
+
+func TestJSONRoundTripControlFlow(t *testing.T) {
+	roundTrip(t, QueryChain(
+		BeginTransaction(),
+		Let("n", 1),
+		If(F[int]("a").Eq(1)).Then(Block{Body: Return(1)}).Else(Block{Body: Throw("no")}),
+		For("x").In(List{Items: []Node{V(1), V(2)}}).Block(Block{Body: BreakStatement{}}),
+		RawStmt("SELECT * FROM $t", map[string]any{"t": "user"}),
+		CommitTransaction(),
+	))
+}
+
+func TestJSONRoundTripKeepsNumberKinds(t *testing.T) {
+	stmt := Update(T("stat")).Set(
+		Set(I("ratio"), 2.0),
+		Set(I("big"), uint64(math.MaxUint64)),
+		Set(I("count"), 3),
+		Set(I("scores"), []float64{1, 0.5}),
+		Set(I("limits"), map[string]any{"max": uint64(math.MaxInt64) + 1, "min": -1.0}),
+	)
+	args := Build(roundTrip(t, stmt)).Args
+	want := map[string]any{
+		"p1": 2.0,
+		"p2": uint64(math.MaxUint64),
+		"p3": int64(3),
+		"p4": []any{1.0, 0.5},
+		"p5": map[string]any{"max": uint64(math.MaxInt64) + 1, "min": -1.0},
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected args: %#v, expected %#v", args, want)
+	}
+	if _, err := MarshalNode(Select().From(T("t")).Where(I("x").Eq(math.NaN()))); !errors.Is(err, ErrUnsupportedValue) {
+		t.Fatalf("expected ErrUnsupportedValue for NaN, got %v", err)
+	}
+}
+
+func TestJSONStatementField(t *testing.T) {
+	type savedQuery struct {
+		Name  string        `json:"name"`
+		Query JSONStatement `json:"query"`
+	}
+	in := savedQuery{Name: "adults", Query: JSONStatement{Statement: Select().From(T("user")).Where(F[int]("age").Gte(18))}}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out savedQuery
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	assertQuery(t, out.Query.Statement, "SELECT * FROM user WHERE age >= $p1")
+	if v := Build(out.Query.Statement).Args["p1"]; v != int64(18) {
+		t.Fatalf("expected int64 arg, got %#v", v)
+	}
+}
+
+func TestJSONUnsupportedNode(t *testing.T) {
+	_, err := MarshalNode(RemoveDatabase("app"))
+	if !errors.Is(err, ErrUnsupportedNode) {
+		t.Fatalf("expected ErrUnsupportedNode, got %v", err)
+	}
+	if _, err := UnmarshalNode([]byte(`{"kind":"nope"}`)); err == nil {
+		t.Fatalf("expected error for unknown kind")
+	}
+}
+
+func TestJSONRefusesTypedValues(t *testing.T) {
+	type recordID struct {
+		Table string
+		ID    any
+	}
+	cases := []Node{
+		Select().From(T("event")).Where(F[time.Time]("at").Gt(time.Unix(0, 0))),
+		Update(T("user")).Set(Set(I("owner"), recordID{Table: "user", ID: 1})),
+		Create(T("doc")).Content(map[string]any{"blob": []byte("x")}),
+		Select().From(T("user")).Where(I("id").Eq(PWith("id", &recordID{Table: "user", ID: 1}))),
+		RawStmt("SELECT * FROM $id", map[string]any{"id": recordID{Table: "user", ID: 1}}),
+	}
+	for _, n := range cases {
+		if _, err := MarshalNode(n); !errors.Is(err, ErrUnsupportedValue) {
+			t.Fatalf("expected ErrUnsupportedValue for %s, got %v", Build(n).Text, err)
+		}
+	}
+}
+
+func TestJSONRejectsIncompleteNodes(t *testing.T) {
+	cases := []string{
+		`{"kind":"binary"}`,
+		`{"kind":"binary","op":"=","left":{"kind":"ident","name":"a"}}`,
+		`{"kind":"unary","op":"-"}`,
+		`{"kind":"ident"}`,
+		`{"kind":"call"}`,
+		`{"kind":"list","items":[null]}`,
+		`{"kind":"select","from":[{"kind":"table","name":"t"}],"where":{"kind":"binary","op":"="}}`,
+		`{"kind":"update","set":[{"kind":"assign","op":"=","left":{"kind":"ident","name":"a"},"right":{"kind":"value","data":1}}]}`,
+		`{"kind":"update","target":{"kind":"table","name":"t"},"set":[{"kind":"ident","name":"a"}]}`,
+		`{"kind":"relate","target":{"kind":"ident","name":"a:1"},"edge":{"kind":"table","name":"e"}}`,
+		`{"kind":"let","name":"x"}`,
+		`{"kind":"if"}`,
+		`{"kind":"for","expr":{"kind":"param","name":"xs"},"body":{"kind":"block","body":{"kind":"break"}}}`,
+	}
+	for _, data := range cases {
+		n, err := UnmarshalNode([]byte(data))
+		if !errors.Is(err, ErrInvalidJSON) {
+			t.Fatalf("expected ErrInvalidJSON for %s, got %v (%T)", data, err, n)
+		}
+	}
+}
@@ -11,6 +11,19 @@ func Set(field Node, value any) Assignment {
 	return Assignment{Field: field, Op: "=", Value: ensureValueNode(value)}
 }
 
+// Inc increments field by value (+=).
+func Inc(field Node, value any) Assignment {
+	return Assignment{Field: field, Op: "+=", Value: ensureValueNode(value)}
+}
+
+func (a Assignment) build(b *Builder) {
+	a.Field.build(b)
+	b.Write(" ")
+	b.Write(a.Op)
+	b.Write(" ")
+	a.Value.build(b)
+}
+
 // CreateBuilder builds CREATE statements.
 type CreateBuilder struct {
 	target    Node
@@ -38,6 +51,21 @@ func (c *CreateBuilder) Return(ret Node) *CreateBuilder {
 	return c
 }
 
+// Target returns the CREATE target.
+func (c *CreateBuilder) Target() Node {
+	return c.target
+}
+
+// ContentValue returns the CONTENT node, or nil when unset.
+func (c *CreateBuilder) ContentValue() Node {
+	return c.content
+}
+
+// Assignments returns the SET assignments.
+func (c *CreateBuilder) Assignments() []Assignment {
+	return c.set
+}
+
 func (c *CreateBuilder) Build() Query {
 	return Build(c)
 }
@@ -82,6 +110,22 @@ func (i *InsertBuilder) Return(ret Node) *InsertBuilder {
 	return i
 }
 
+// Target returns the INSERT INTO target.
+func (i *InsertBuilder) Target() Node {
+	return i.into
+}
+
+// Rows returns the inserted values.
+func (i *InsertBuilder) Rows() []Node {
+	return i.values
+}
+
+// SetRows replaces the inserted values.
+func (i *InsertBuilder) SetRows(rows ...Node) *InsertBuilder {
+	i.values = rows
+	return i
+}
+
 func (i *InsertBuilder) Build() Query {
 	return Build(i)
 }
@@ -102,6 +146,8 @@ func (i *InsertBuilder) build(b *Builder) {
 // UpdateBuilder builds UPDATE statements.
 type UpdateBuilder struct {
 	target    Node
+	dataMode  string
+	data      Node
 	set       []Assignment
 	where     Condition
 	returning Node
@@ -116,6 +162,20 @@ func (u *UpdateBuilder) Set(assignments ...Assignment) *UpdateBuilder {
 	return u
 }
 
+// Content replaces the record with value (CONTENT).
+func (u *UpdateBuilder) Content(value any) *UpdateBuilder {
+	u.dataMode = "CONTENT"
+	u.data = ensureValueNode(value)
+	return u
+}
+
+// Merge merges value into the record (MERGE).
+func (u *UpdateBuilder) Merge(value any) *UpdateBuilder {
+	u.dataMode = "MERGE"
+	u.data = ensureValueNode(value)
+	return u
+}
+
 func (u *UpdateBuilder) Where(cond Condition) *UpdateBuilder {
 	u.where = cond
 	return u
@@ -126,6 +186,31 @@ func (u *UpdateBuilder) Return(ret Node) *UpdateBuilder {
 	return u
 }
 
+// Target returns the UPDATE target.
+func (u *UpdateBuilder) Target() Node {
+	return u.target
+}
+
+// Data returns the CONTENT or MERGE node, or nil when unset.
+func (u *UpdateBuilder) Data() Node {
+	return u.data
+}
+
+// DataMode returns "CONTENT" or "MERGE" for the node returned by Data.
+func (u *UpdateBuilder) DataMode() string {
+	return u.dataMode
+}
+
+// Assignments returns the SET assignments.
+func (u *UpdateBuilder) Assignments() []Assignment {
+	return u.set
+}
+
+// WhereCond returns the WHERE condition, or an empty Condition when unset.
+func (u *UpdateBuilder) WhereCond() Condition {
+	return u.where
+}
+
 func (u *UpdateBuilder) Build() Query {
 	return Build(u)
 }
@@ -133,6 +218,12 @@ func (u *UpdateBuilder) Build() Query {
 func (u *UpdateBuilder) build(b *Builder) {
 	b.Write("UPDATE ")
 	u.target.build(b)
+	if u.data != nil {
+		b.Write(" ")
+		b.Write(u.dataMode)
+		b.Write(" ")
+		u.data.build(b)
+	}
 	if len(u.set) > 0 {
 		b.Write(" SET ")
 		renderAssignments(b, u.set)
@@ -168,6 +259,16 @@ func (d *DeleteBuilder) Return(ret Node) *DeleteBuilder {
 	return d
 }
 
+// Target returns the DELETE target.
+func (d *DeleteBuilder) Target() Node {
+	return d.target
+}
+
+// WhereCond returns the WHERE condition, or an empty Condition when unset.
+func (d *DeleteBuilder) WhereCond() Condition {
+	return d.where
+}
+
 func (d *DeleteBuilder) Build() Query {
 	return Build(d)
 }
@@ -190,10 +291,6 @@ func renderAssignments(b *Builder, assigns []Assignment) {
 		if i > 0 {
 			b.Write(", ")
 		}
-		a.Field.build(b)
-		b.Write(" ")
-		b.Write(a.Op)
-		b.Write(" ")
-		a.Value.build(b)
+		a.build(b)
 	}
 }
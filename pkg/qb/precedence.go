@@ -0,0 +1,126 @@
+package qb
+
+import "strings"
+
+// Operator binding powers, lowest first. Identifiers, values, calls, lists
+// and parenthesized subqueries bind as atoms.
+const (
+	precUnknown = iota
+	precOr
+	precAnd
+	precEquality
+	precRelation
+	precAddSub
+	precMulDiv
+	precPower
+	precPrefix
+	precAtom
+)
+
+var binaryPrecedence = map[string]int{
+	"OR": precOr, "||": precOr,
+	"AND": precAnd, "&&": precAnd,
+
+	"=": precEquality, "==": precEquality, "!=": precEquality,
+	"?=": precEquality, "*=": precEquality,
+	"~": precEquality, "!~": precEquality, "?~": precEquality, "*~": precEquality,
+	"IS": precEquality, "IS NOT": precEquality,
+
+	"<": precRelation, "<=": precRelation, ">": precRelation, ">=": precRelation,
+	"CONTAINS": precRelation, "CONTAINSNOT": precRelation,
+	"CONTAINSALL": precRelation, "CONTAINSANY": precRelation, "CONTAINSNONE": precRelation,
+	"INSIDE": precRelation, "NOTINSIDE": precRelation, "IN": precRelation, "NOT IN": precRelation,
+	"ALLINSIDE": precRelation, "ANYINSIDE": precRelation, "NONEINSIDE": precRelation,
+	"OUTSIDE": precRelation, "INTERSECTS": precRelation,
+
+	"+": precAddSub, "-": precAddSub,
+	"*": precMulDiv, "/": precMulDiv, "%": precMulDiv,
+	"**": precPower,
+}
+
+// associativeOps may be regrouped freely, so a right operand using the same
+// operator does not need parentheses.
+var associativeOps = map[string]bool{
+	"OR": true, "||": true, "AND": true, "&&": true, "+": true, "*": true,
+}
+
+// fieldNode is implemented by every Field[T], which a type switch cannot
+// name without its type parameter.
+type fieldNode interface{ fieldNode() }
+
+func opPrecedence(op string) int {
+	return binaryPrecedence[strings.ToUpper(op)]
+}
+
+func unwrapNode(n Node) Node {
+	for {
+		e, ok := n.(interface{ unwrap() Node })
+		if !ok {
+			return n
+		}
+		n = e.unwrap()
+	}
+}
+
+// nodePrecedence reports how tightly a node binds when used as an operand.
+// Anything that is not known to be atomic reports precUnknown and is always
+// parenthesized, so raw fragments keep their meaning.
+func nodePrecedence(n Node) int {
+	switch t := unwrapNode(n).(type) {
+	case nil:
+		return precAtom
+	case Binary:
+		return opPrecedence(t.Op)
+	case Unary:
+		return precPrefix
+	case RawExpr:
+		text := strings.TrimSpace(t.Text)
+		if strings.ContainsAny(text, " \t\n") {
+			return precUnknown
+		}
+		if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "!") {
+			return precPrefix
+		}
+		return precAtom
+	case Value, Param, Ident, FuncCall, List, Table, Subquery, Block, Graph, fieldNode:
+		return precAtom
+	default:
+		// Statements such as a *SelectBuilder passed as a value.
+		return precUnknown
+	}
+}
+
+// needsParens decides whether an operand of op must be wrapped to keep the
+// tree's meaning. Same-level chains are flattened on the left, and on the
+// right only for the same associative operator. Comparisons never chain.
+func needsParens(op string, operand Node, right bool) bool {
+	parent := opPrecedence(op)
+	child := nodePrecedence(operand)
+	if child == precUnknown {
+		return true
+	}
+	if parent == precUnknown {
+		return child < precPrefix
+	}
+	if child != parent {
+		return child < parent
+	}
+	if parent == precEquality || parent == precRelation {
+		return true
+	}
+	if !right {
+		return false
+	}
+	inner, ok := unwrapNode(operand).(Binary)
+	return !ok || !associativeOps[strings.ToUpper(op)] || !strings.EqualFold(inner.Op, op)
+}
+
+func writeOperand(b *Builder, n Node, parens bool) {
+	if parens {
+		b.Write("(")
+	}
+	n.build(b)
+	if parens {
+		b.Write(")")
+	}
+}
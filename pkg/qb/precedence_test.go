@@ -0,0 +1,124 @@
+package qb
+
+import "testing"
+
+func TestPrecedenceRendering(t *testing.T) {
+	a, b, c, d, e := I("a"), I("b"), I("c"), I("d"), I("e")
+	cases := []struct {
+		name string
+		node Node
+		want string
+	}{
+		{
+			name: "flat and chain",
+			node: And(a.Eq(1), b.Eq(2), c.Eq(3), d.Eq(4), e.Eq(5)),
+			want: "a = $p1 AND b = $p2 AND c = $p3 AND d = $p4 AND e = $p5",
+		},
+		{
+			name: "or inside and",
+			node: And(a.Eq(1), Or(b.Eq(2), c.Eq(3))),
+			want: "a = $p1 AND (b = $p2 OR c = $p3)",
+		},
+		{
+			name: "and inside or",
+			node: Or(And(a.Eq(1), b.Eq(2)), c.Eq(3)),
+			want: "a = $p1 AND b = $p2 OR c = $p3",
+		},
+		{
+			name: "nested same operator on right",
+			node: And(a.Eq(1), And(b.Eq(2), c.Eq(3))),
+			want: "a = $p1 AND b = $p2 AND c = $p3",
+		},
+		{
+			name: "not over binary",
+			node: Not(a.Eq(1)),
+			want: "!(a = $p1)",
+		},
+		{
+			name: "not over ident",
+			node: Not(Expr[bool]{node: Ident{Name: "flag"}}),
+			want: "!flag",
+		},
+		{
+			name: "arithmetic precedence",
+			node: a.Add(b.Mul(c)),
+			want: "a + b * c",
+		},
+		{
+			name: "grouping beats precedence",
+			node: a.Add(b).Mul(c),
+			want: "(a + b) * c",
+		},
+		{
+			name: "subtraction is not associative",
+			node: a.Sub(b.Sub(c)),
+			want: "a - (b - c)",
+		},
+		{
+			name: "left nested subtraction",
+			node: a.Sub(b).Sub(c),
+			want: "a - b - c",
+		},
+		{
+			name: "mixed additive on right",
+			node: a.Add(b.Sub(c)),
+			want: "a + (b - c)",
+		},
+		{
+			name: "comparison of arithmetic",
+			node: a.Add(1).Gt(b.Mul(2)),
+			want: "a + $p1 > b * $p2",
+		},
+		{
+			name: "comparison chain stays grouped",
+			node: a.Eq(b).Eq(true),
+			want: "(a = b) = $p1",
+		},
+		{
+			name: "raw fragment is protected",
+			node: And(RawCond("x = 1 OR y = 2"), a.Eq(1)),
+			want: "(x = 1 OR y = 2) AND a = $p1",
+		},
+		{
+			name: "raw atom is not wrapped",
+			node: And(RawCond("true"), a.Eq(1)),
+			want: "true AND a = $p1",
+		},
+		{
+			name: "unknown operator keeps parentheses",
+			node: Binary{Left: a.Eq(1), Op: "??", Right: b},
+			want: "(a = $p1) ?? b",
+		},
+		{
+			name: "subquery operand",
+			node: a.In(Subquery{Stmt: Select(I("id")).From(T("user"))}),
+			want: "a IN [(SELECT id FROM user)]",
+		},
+		{
+			name: "statement operand is parenthesized",
+			node: And(a.Eq(Select(b).From(T("t")).Where(c.Eq(1))), d.Eq(2)),
+			want: "a = (SELECT b FROM t WHERE c = $p1) AND d = $p2",
+		},
+		{
+			name: "nested negation is not a comment",
+			node: Unary{Op: "-", Expr: Unary{Op: "-", Expr: a}},
+			want: "-(-a)",
+		},
+		{
+			name: "negated negative raw",
+			node: Unary{Op: "-", Expr: RawExpr{Text: "-1"}},
+			want: "-(-1)",
+		},
+		{
+			name: "double not",
+			node: Not(Not(a.Eq(1))),
+			want: "!(!(a = $p1))",
+		},
+	}
+	for _, tc := range cases {
+		q := Build(Return(tc.node))
+		if q.Text != "RETURN "+tc.want {
+			t.Fatalf("%s:\n got: %s\nwant: RETURN %s", tc.name, q.Text, tc.want)
+		}
+	}
+}
@@ -23,6 +23,16 @@ func (r *RelateBuilder) Return(ret Node) *RelateBuilder {
 	return r
 }
 
+// Edge returns the edge table or record.
+func (r *RelateBuilder) Edge() Node {
+	return r.edge
+}
+
+// Assignments returns the SET assignments.
+func (r *RelateBuilder) Assignments() []Assignment {
+	return r.set
+}
+
 func (r *RelateBuilder) Build() Query {
 	return Build(r)
 }
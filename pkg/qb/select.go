@@ -96,6 +96,28 @@ func (s *SelectBuilder) Parallel() *SelectBuilder {
 	return s
 }
 
+// Targets returns the FROM targets.
+func (s *SelectBuilder) Targets() []Node {
+	return s.from
+}
+
+// WhereCond returns the WHERE condition, or an empty Condition when unset.
+func (s *SelectBuilder) WhereCond() Condition {
+	return s.where
+}
+
+// Clone returns a copy of the builder that can be changed independently.
+func (s *SelectBuilder) Clone() *SelectBuilder {
+	c := *s
+	c.projections = append([]Projection(nil), s.projections...)
+	c.from = append([]Node(nil), s.from...)
+	c.orders = append([]Order(nil), s.orders...)
+	c.groupBy = append([]Node(nil), s.groupBy...)
+	c.splitOn = append([]Node(nil), s.splitOn...)
+	c.fetch = append([]Node(nil), s.fetch...)
+	return &c
+}
+
 // Build renders the query.
 func (s *SelectBuilder) Build() Query {
 	return Build(s)
@@ -14,7 +14,7 @@ func TestSelectBuilder(t *testing.T) {
 		Timeout("5s").
 		Parallel()
 
-	expected := "SELECT * FROM user WHERE (age > $p1) SPLIT tags GROUP BY role ORDER BY name COLLATE ASC, id RAND() LIMIT $p2 START $p3 FETCH profile TIMEOUT $p4 PARALLEL"
+	expected := "SELECT * FROM user WHERE age > $p1 SPLIT tags GROUP BY role ORDER BY name COLLATE ASC, id RAND() LIMIT $p2 START $p3 FETCH profile TIMEOUT $p4 PARALLEL"
 	q := assertQuery(t, stmt, expected)
 	assertArgsLen(t, q, 4)
 }
@@ -34,13 +34,13 @@ func TestModifyBuilders(t *testing.T) {
 
 	update := Update(T("user")).Set(Set(I("name"), "b")).Where(I("id").Eq("u:1")).Return(I("id"))
 	q = Build(update)
-	if q.Text != "UPDATE user SET name = $p1 WHERE (id = $p2) RETURN id" {
+	if q.Text != "UPDATE user SET name = $p1 WHERE id = $p2 RETURN id" {
 		t.Fatalf("unexpected update: %s", q.Text)
 	}
 
 	del := Delete(T("user")).Where(I("id").Eq("u:1")).Return(I("id"))
 	q = Build(del)
-	if q.Text != "DELETE user WHERE (id = $p1) RETURN id" {
+	if q.Text != "DELETE user WHERE id = $p1 RETURN id" {
 		t.Fatalf("unexpected delete: %s", q.Text)
 	}
 
@@ -67,7 +67,7 @@ func TestIfForFlowAndTransaction(t *testing.T) {
 	ifStmt := If(I("a").Eq(1)).Then(Raw("do()"))
 	ifStmt.ElseIf(I("b").Eq(2)).Then(Raw("do2()"))
 	ifStmt.Else(Raw("do3()"))
-	assertQuery(t, ifStmt, "IF (a = $p1) { do() } ELSE IF (b = $p2) { do2() } ELSE { do3() } END")
+	assertQuery(t, ifStmt, "IF a = $p1 { do() } ELSE IF b = $p2 { do2() } ELSE { do3() } END")
 
 	forStmt := For("$item", "idx").In(L(V(1), V(2))).Block(Raw("do()"))
 	assertQuery(t, forStmt, "FOR $item, $idx IN [$p1, $p2] { do() }")
@@ -124,3 +124,9 @@ func TestDefineAccessBearer(t *testing.T) {
 		t.Fatalf("unexpected bearer access: %s", q.Text)
 	}
 }
+
+func TestUpdateContentAndMerge(t *testing.T) {
+	q := assertQuery(t, Update(I("user:1")).Merge(map[string]any{"name": "x"}).Where(I("v").Eq(1)), "UPDATE user:1 MERGE $p1 WHERE v = $p2")
+	assertArgsLen(t, q, 2)
+	assertQuery(t, Update(I("user:1")).Content(P("data")), "UPDATE user:1 CONTENT $data")
+}
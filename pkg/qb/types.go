@@ -28,6 +28,8 @@ func (f Field[T]) build(b *Builder) {
 	b.Write(f.Name)
 }
 
+func (Field[T]) fieldNode() {}
+
 func (f Field[T]) As(alias string) Expr[any] {
 	return As(f, alias)
 }
@@ -0,0 +1,355 @@
+package qb
+
+// FieldRef is implemented by every Field[T], whatever its value type, so
+// visitors can recognise field references without knowing T.
+type FieldRef interface {
+	Node
+	FieldName() string
+}
+
+func (f Field[T]) FieldName() string {
+	return f.Name
+}
+
+// Walk traverses the tree rooted at node depth-first. fn is called for each
+// node before its children; returning false skips the children. Typed
+// expression wrappers (Expr[T], Condition) are transparent: fn sees the
+// concrete node they hold.
+func Walk(node Node, fn func(Node) bool) {
+	node = unwrapNode(node)
+	if node == nil {
+		return
+	}
+	if !fn(node) {
+		return
+	}
+	for _, child := range children(node) {
+		if child != nil {
+			Walk(child, fn)
+		}
+	}
+}
+
+// Rewrite returns a copy of the tree rooted at node with fn applied
+// bottom-up: children are rewritten first, then fn receives the rebuilt
+// parent and returns its replacement. Returning nil removes the child from
+// its parent where the parent can do without it: list items, function
+// arguments, projections, FROM targets, ORDER BY, GROUP BY, SPLIT and FETCH
+// entries, SET assignments and the statements of a Chain are dropped, and
+// the optional WHERE, LIMIT, START, TIMEOUT, CONTENT, RETURN and ELSE
+// clauses are cleared. Every other child (operands, graph starts, statement
+// targets, subquery statements, assignment sides, block bodies,
+// LET/RETURN/THROW values, IF conditions and FOR iterables) is required and
+// keeps its original node. DML and control-flow builders passed to fn are fresh copies, so fn
+// may modify them in place without touching the original tree.
+func Rewrite(node Node, fn func(Node) Node) Node {
+	node = unwrapNode(node)
+	if node == nil {
+		return nil
+	}
+	kids := children(node)
+	if len(kids) > 0 {
+		rewritten := make([]Node, len(kids))
+		for i, child := range kids {
+			rewritten[i] = Rewrite(child, fn)
+		}
+		node = withChildren(node, rewritten)
+	} else {
+		node = withChildren(node, nil)
+	}
+	return fn(node)
+}
+
+// RewriteStatement is Rewrite for statements.
+func RewriteStatement(stmt Statement, fn func(Node) Node) Statement {
+	out := Rewrite(stmt, fn)
+	if out == nil {
+		return nil
+	}
+	return out.(Statement)
+}
+
+// children lists the direct sub-nodes of n in a fixed order. Missing optional
+// clauses are reported as nil so withChildren can put them back in place.
+func children(n Node) []Node {
+	switch t := n.(type) {
+	case Alias:
+		return []Node{t.Expr}
+	case Graph:
+		return []Node{t.From}
+	case Binary:
+		return []Node{t.Left, t.Right}
+	case Unary:
+		return []Node{t.Expr}
+	case FuncCall:
+		return t.Args
+	case List:
+		return t.Items
+	case Subquery:
+		return []Node{t.Stmt}
+	case StmtExpr:
+		return []Node{t.Stmt}
+	case Block:
+		return []Node{t.Body}
+	case Chain:
+		out := make([]Node, 0, len(t.Statements))
+		for _, s := range t.Statements {
+			out = append(out, s)
+		}
+		return out
+	case Order:
+		return []Node{t.field}
+	case Assignment:
+		return []Node{t.Field, t.Value}
+	case *SelectBuilder:
+		var out []Node
+		out = append(out, t.projections...)
+		out = append(out, t.from...)
+		out = append(out, t.where.node)
+		for _, o := range t.orders {
+			out = append(out, o)
+		}
+		out = append(out, t.groupBy...)
+		out = append(out, t.splitOn...)
+		out = append(out, t.limit, t.start)
+		out = append(out, t.fetch...)
+		return append(out, t.timeout)
+	case *CreateBuilder:
+		out := []Node{t.target, t.content}
+		out = appendAssignments(out, t.set)
+		return append(out, t.returning)
+	case *InsertBuilder:
+		out := []Node{t.into}
+		out = append(out, t.values...)
+		return append(out, t.returning)
+	case *UpdateBuilder:
+		out := []Node{t.target, t.data}
+		out = appendAssignments(out, t.set)
+		return append(out, t.where.node, t.returning)
+	case *DeleteBuilder:
+		return []Node{t.target, t.where.node, t.returning}
+	case *RelateBuilder:
+		out := []Node{t.from, t.edge, t.to}
+		out = appendAssignments(out, t.set)
+		return append(out, t.returning)
+	case *ReturnStatement:
+		return []Node{t.Value}
+	case *LetStatement:
+		return []Node{t.Value}
+	case *ThrowStatement:
+		return []Node{t.Value}
+	case *IfBuilder:
+		var out []Node
+		for _, br := range t.branches {
+			out = append(out, br.Cond.node, br.Body)
+		}
+		return append(out, t.elseBody)
+	case *ForBuilder:
+		return []Node{t.iterable, t.body}
+	}
+	return nil
+}
+
+// withChildren returns a copy of n with its children replaced, in the order
+// produced by children. Nodes without children are returned as copies.
+func withChildren(n Node, kids []Node) Node {
+	r := &childReader{nodes: kids}
+	switch t := n.(type) {
+	case Alias:
+		t.Expr = r.required(t.Expr)
+		return t
+	case Graph:
+		t.From = r.required(t.From)
+		return t
+	case Binary:
+		t.Left, t.Right = r.required(t.Left), r.required(t.Right)
+		return t
+	case Unary:
+		t.Expr = r.required(t.Expr)
+		return t
+	case FuncCall:
+		t.Args = r.take(len(t.Args))
+		return t
+	case List:
+		t.Items = r.take(len(t.Items))
+		return t
+	case Subquery:
+		t.Stmt = asStatement(r.next(), t.Stmt)
+		return t
+	case StmtExpr:
+		t.Stmt = asStatement(r.next(), t.Stmt)
+		return t
+	case Block:
+		t.Body = r.required(t.Body)
+		return t
+	case Chain:
+		stmts := make([]Statement, 0, len(t.Statements))
+		for _, s := range t.Statements {
+			if n := r.next(); n != nil {
+				stmts = append(stmts, asStatement(n, s))
+			}
+		}
+		t.Statements = stmts
+		return t
+	case Order:
+		t.field = r.required(t.field)
+		return t
+	case Assignment:
+		t.Field, t.Value = r.required(t.Field), r.required(t.Value)
+		return t
+	case *SelectBuilder:
+		c := *t
+		c.projections = r.take(len(t.projections))
+		c.from = r.take(len(t.from))
+		c.where = Condition{node: r.next()}
+		c.orders = nil
+		for _, o := range t.orders {
+			if n := r.next(); n != nil {
+				c.orders = append(c.orders, asOrder(n, o))
+			}
+		}
+		c.groupBy = r.take(len(t.groupBy))
+		c.splitOn = r.take(len(t.splitOn))
+		c.limit, c.start = r.next(), r.next()
+		c.fetch = r.take(len(t.fetch))
+		c.timeout = r.next()
+		return &c
+	case *CreateBuilder:
+		c := *t
+		c.target, c.content = r.required(t.target), r.next()
+		c.set = r.assignments(t.set)
+		c.returning = r.next()
+		return &c
+	case *InsertBuilder:
+		c := *t
+		c.into = r.required(t.into)
+		c.values = r.take(len(t.values))
+		c.returning = r.next()
+		return &c
+	case *UpdateBuilder:
+		c := *t
+		c.target, c.data = r.required(t.target), r.next()
+		c.set = r.assignments(t.set)
+		c.where = Condition{node: r.next()}
+		c.returning = r.next()
+		return &c
+	case *DeleteBuilder:
+		c := *t
+		c.target = r.required(t.target)
+		c.where = Condition{node: r.next()}
+		c.returning = r.next()
+		return &c
+	case *RelateBuilder:
+		c := *t
+		c.from, c.edge, c.to = r.required(t.from), r.required(t.edge), r.required(t.to)
+		c.set = r.assignments(t.set)
+		c.returning = r.next()
+		return &c
+	case *ReturnStatement:
+		c := *t
+		c.Value = r.required(t.Value)
+		return &c
+	case *LetStatement:
+		c := *t
+		c.Value = r.required(t.Value)
+		return &c
+	case *ThrowStatement:
+		c := *t
+		c.Value = r.required(t.Value)
+		return &c
+	case *IfBuilder:
+		c := *t
+		c.branches = make([]ifBranch, len(t.branches))
+		for i, br := range t.branches {
+			c.branches[i] = ifBranch{Cond: Condition{node: r.required(br.Cond.node)}, Body: r.required(br.Body)}
+		}
+		c.elseBody = r.next()
+		return &c
+	case *ForBuilder:
+		c := *t
+		c.params = append([]string(nil), t.params...)
+		c.iterable, c.body = r.required(t.iterable), r.required(t.body)
+		return &c
+	}
+	return n
+}
+
+func appendAssignments(out []Node, assigns []Assignment) []Node {
+	for _, a := range assigns {
+		out = append(out, a)
+	}
+	return out
+}
+
+type childReader struct {
+	nodes []Node
+}
+
+func (r *childReader) next() Node {
+	if len(r.nodes) == 0 {
+		return nil
+	}
+	n := r.nodes[0]
+	r.nodes = r.nodes[1:]
+	return n
+}
+
+// required returns the next child, or orig when the rewrite dropped it.
+func (r *childReader) required(orig Node) Node {
+	if n := r.next(); n != nil {
+		return n
+	}
+	return orig
+}
+
+func (r *childReader) take(count int) []Node {
+	if count == 0 {
+		return nil
+	}
+	out := make([]Node, 0, count)
+	for i := 0; i < count; i++ {
+		if n := r.next(); n != nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+func (r *childReader) assignments(orig []Assignment) []Assignment {
+	if len(orig) == 0 {
+		return nil
+	}
+	out := make([]Assignment, 0, len(orig))
+	for _, a := range orig {
+		switch n := r.next().(type) {
+		case nil:
+		case Assignment:
+			out = append(out, n)
+		default:
+			out = append(out, a)
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func asStatement(n Node, fallback Statement) Statement {
+	if s, ok := n.(Statement); ok && n != nil {
+		return s
+	}
+	return fallback
+}
+
+func asOrder(n Node, fallback Order) Order {
+	switch t := n.(type) {
+	case Order:
+		return t
+	case nil:
+		return fallback
+	default:
+		fallback.field = t
+		return fallback
+	}
+}
@@ -0,0 +1,178 @@
+package qb
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWalkCollectsTablesAndFields(t *testing.T) {
+	stmt := Select(F[string]("name"), As(Fn("count"), "n")).
+		From(T("user")).
+		Where(And(F[int]("age").Gt(18), I("role").In("admin", "owner"))).
+		OrderBy(OrderBy(F[string]("created_at")).Desc())
+
+	var tables, fields []string
+	Walk(stmt, func(n Node) bool {
+		switch t := n.(type) {
+		case Table:
+			tables = append(tables, t.Name())
+		case Ident:
+			fields = append(fields, t.Name)
+		case FieldRef:
+			fields = append(fields, t.FieldName())
+		}
+		return true
+	})
+	sort.Strings(fields)
+	if !reflect.DeepEqual(tables, []string{"user"}) {
+		t.Fatalf("unexpected tables: %v", tables)
+	}
+	if !reflect.DeepEqual(fields, []string{"age", "created_at", "name", "role"}) {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+}
+
+func TestWalkSkipsChildren(t *testing.T) {
+	stmt := Select().From(T("user")).Where(F[int]("id").In(Subquery{Stmt: Select(I("x")).From(T("inner"))}))
+	var tables []string
+	Walk(stmt, func(n Node) bool {
+		if _, ok := n.(Subquery); ok {
+			return false
+		}
+		if t, ok := n.(Table); ok {
+			tables = append(tables, t.Name())
+		}
+		return true
+	})
+	if !reflect.DeepEqual(tables, []string{"user"}) {
+		t.Fatalf("expected subquery to be skipped, got %v", tables)
+	}
+}
+
+func TestRewriteInjectsFilter(t *testing.T) {
+	orig := Select().From(T("post")).Where(F[bool]("published").Eq(true))
+	tenant := F[string]("tenant").Eq(PWith("tenant", "acme"))
+
+	out := RewriteStatement(orig, func(n Node) Node {
+		if s, ok := n.(*SelectBuilder); ok {
+			if s.WhereCond().IsZero() {
+				return s.Where(tenant)
+			}
+			return s.Where(And(s.WhereCond(), tenant))
+		}
+		return n
+	})
+
+	assertQuery(t, out, "SELECT * FROM post WHERE published = $p1 AND tenant = $tenant")
+	assertQuery(t, orig, "SELECT * FROM post WHERE published = $p1")
+}
+
+func TestRewriteRedactsValues(t *testing.T) {
+	stmt := QueryChain(
+		Update(T("user")).Set(Set(I("password"), "secret")).Where(I("id").Eq(1)),
+		Create(T("log")).Content(map[string]any{"msg": "x"}),
+		Delete(T("user")).Where(Not(I("active").Eq(false))),
+		Relate(I("a"), T("knows"), I("b")).Set(Set(I("since"), 2020)),
+		Insert(T("t")).Values(1),
+		If(I("x").Eq(1)).Then(Return(2)).Else(Throw("boom")),
+		For("item").In(L(V(1))).Block(Let("y", 3)),
+	)
+	out := RewriteStatement(stmt, func(n Node) Node {
+		if _, ok := n.(Value); ok {
+			return Raw("'***'")
+		}
+		return n
+	})
+	q := Build(out)
+	if len(q.Args) != 0 {
+		t.Fatalf("expected all values redacted, got %v", q.Args)
+	}
+	want := "UPDATE user SET password = '***' WHERE id = '***'; " +
+		"CREATE log CONTENT '***'; " +
+		"DELETE user WHERE !(active = '***'); " +
+		"RELATE a -> knows -> b SET since = '***'; " +
+		"INSERT INTO t '***'; " +
+		"IF x = '***' { RETURN '***' } ELSE { THROW '***' } END; " +
+		"FOR $item IN ['***'] { LET $y = '***' }"
+	if q.Text != want {
+		t.Fatalf("unexpected rewrite:\n%s\nexpected:\n%s", q.Text, want)
+	}
+	if got := Build(stmt); len(got.Args) == 0 {
+		t.Fatalf("expected original statement to keep its values")
+	}
+}
+
+func TestStatementAccessors(t *testing.T) {
+	c := Create(T("user")).Content(1).Set(Set(I("a"), 1))
+	if c.Target() != T("user") || c.ContentValue() == nil || len(c.Assignments()) != 1 {
+		t.Fatalf("unexpected create accessors")
+	}
+	i := Insert(T("user")).Values(1, 2)
+	if i.Target() != T("user") || len(i.Rows()) != 2 {
+		t.Fatalf("unexpected insert accessors")
+	}
+	u := Update(T("user")).Set(Set(I("a"), 1)).Where(I("a").Eq(1))
+	if u.Target() != T("user") || len(u.Assignments()) != 1 || u.WhereCond().IsZero() {
+		t.Fatalf("unexpected update accessors")
+	}
+	d := Delete(T("user"))
+	if d.Target() != T("user") || !d.WhereCond().IsZero() {
+		t.Fatalf("unexpected delete accessors")
+	}
+	r := Relate(I("a"), T("e"), I("b")).Set(Set(I("x"), 1))
+	if r.Edge() != T("e") || len(r.Assignments()) != 1 {
+		t.Fatalf("unexpected relate accessors")
+	}
+	s := Select().From(T("a"), T("b"))
+	if len(s.Targets()) != 2 || s.WhereCond().Node() != nil {
+		t.Fatalf("unexpected select accessors")
+	}
+}
+
+func TestRewriteDropsAssignments(t *testing.T) {
+	stmt := Update(T("user")).Set(Set(I("name"), "ann"), Set(I("tenant"), "evil"))
+	out := RewriteStatement(stmt, func(n Node) Node {
+		if a, ok := n.(Assignment); ok && a.Field == Node(Ident{Name: "tenant"}) {
+			return nil
+		}
+		return n
+	})
+	assertQuery(t, out, "UPDATE user SET name = $p1")
+	assertQuery(t, stmt, "UPDATE user SET name = $p1, tenant = $p2")
+}
+
+func TestRewriteDropsChainedStatements(t *testing.T) {
+	stmt := QueryChain(Let("x", 1), Delete(T("user")), Return(I("x")))
+	out := RewriteStatement(stmt, func(n Node) Node {
+		if _, ok := n.(*DeleteBuilder); ok {
+			return nil
+		}
+		return n
+	})
+	assertQuery(t, out, "LET $x = $p1; RETURN x")
+}
+
+func TestRewriteDropsOptionalClauses(t *testing.T) {
+	stmt := Select().From(T("user")).Where(I("age").Gt(18)).OrderBy(OrderBy(I("name")))
+	out := RewriteStatement(stmt, func(n Node) Node {
+		switch n.(type) {
+		case Binary, Order:
+			return nil
+		}
+		return n
+	})
+	assertQuery(t, out, "SELECT * FROM user")
+}
+
+func TestRewriteKeepsRequiredChildren(t *testing.T) {
+	stmt := Delete(T("user")).Where(I("age").Gt(18))
+	out := RewriteStatement(stmt, func(n Node) Node {
+		switch n.(type) {
+		case Table, Ident:
+			return nil
+		}
+		return n
+	})
+	assertQuery(t, out, "DELETE user WHERE age > $p1")
+}
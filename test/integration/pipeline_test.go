@@ -2,29 +2,18 @@ package integration
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
-	"strings"
 	"testing"
 
 	"github.com/yaroher/surrealdb.go.orm/internal/codegen"
 	"github.com/yaroher/surrealdb.go.orm/pkg/migrator"
+	"github.com/yaroher/surrealdb.go.orm/pkg/ormtest"
 )
 
-type stubDB struct {
-	calls []string
-	fn    func(sql string) ([]map[string]any, error)
-}
-
-func (s *stubDB) Query(ctx context.Context, sql string, vars map[string]any) ([]map[string]any, error) {
-	s.calls = append(s.calls, sql)
-	if s.fn != nil {
-		return s.fn(sql)
-	}
-	return nil, nil
-}
-
 func TestIntegrationGenerateAndApply(t *testing.T) {
+	ctx := context.Background()
 	codeDir := t.TempDir()
 	src := `package sample
 
@@ -41,26 +30,17 @@ type User struct { Name string }
 	}
 	code := codegen.BuildResourceSet(pkg.Models)
 
-	introspectDB := &stubDB{fn: func(sql string) ([]map[string]any, error) {
-		if sql == "INFO FOR DB" {
-			return []map[string]any{{"tables": map[string]any{}, "accesses": map[string]any{}}}, nil
-		}
-		return nil, nil
-	}}
-	dbResources, err := migrator.Introspect(context.Background(), introspectDB)
+	db := ormtest.New()
+	migDir := t.TempDir()
+	m := migrator.New(db, migrator.Config{Dir: migDir, TwoWay: true, Mode: migrator.ModeStrict}, migrator.NoPrompter{})
+	if err := m.Init(ctx); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	dbResources, err := migrator.Introspect(ctx, db)
 	if err != nil {
 		t.Fatalf("introspect: %v", err)
 	}
-
-	migDir := t.TempDir()
-	applyDB := &stubDB{fn: func(sql string) ([]map[string]any, error) {
-		if strings.HasPrefix(sql, "SELECT id, checksum") {
-			return nil, nil
-		}
-		return nil, nil
-	}}
-	m := migrator.New(applyDB, migrator.Config{Dir: migDir, TwoWay: true, Mode: migrator.ModeLax}, migrator.NoPrompter{})
-	if _, err := m.Generate(context.Background(), code, dbResources, "init"); err != nil {
+	if _, err := m.Generate(ctx, code, dbResources, "init"); err != nil {
 		t.Fatalf("generate: %v", err)
 	}
 
@@ -72,18 +52,37 @@ type User struct { Name string }
 		t.Fatalf("expected migration files")
 	}
 
-	if err := m.Up(context.Background(), 0); err != nil {
+	if err := m.Up(ctx, 0); err != nil {
 		t.Fatalf("up: %v", err)
 	}
+	status, err := m.List(ctx)
+	if err != nil || len(status) != 1 || !status[0].Applied {
+		t.Fatalf("expected applied migration, got %+v %v", status, err)
+	}
 
-	found := false
-	for _, call := range applyDB.calls {
-		if strings.Contains(call, "DEFINE TABLE users") {
-			found = true
-			break
-		}
-	}
-	if !found {
-		t.Fatalf("expected define table users in DB calls")
+	// The applied schema now matches the code.
+	dbResources, err = migrator.Introspect(ctx, db)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if _, ok := dbResources.Tables["users"]; !ok {
+		t.Fatalf("expected users table in database, got %v", dbResources.Tables)
+	}
+	if _, err := m.Generate(ctx, code, dbResources, "again"); !errors.Is(err, migrator.ErrNoChanges) {
+		t.Fatalf("expected no changes after apply, got %v", err)
+	}
+
+	if err := m.Down(ctx, 1); err != nil {
+		t.Fatalf("down: %v", err)
+	}
+	dbResources, err = migrator.Introspect(ctx, db)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if len(dbResources.Tables) != 0 {
+		t.Fatalf("expected rollback to remove tables, got %v", dbResources.Tables)
+	}
+	if records := db.Records("_migrations"); len(records) != 0 {
+		t.Fatalf("expected migration record to be removed, got %v", records)
 	}
 }